@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"video-gallery/pkg/config"
+	"video-gallery/pkg/handlers"
+	"video-gallery/pkg/router"
+)
+
+// mountAdminRoutes wires the CRUD admin router (upload/rename/delete) into
+// rt at /{SecretKey}/admin/, gated behind RequireAdmin (HTTP Basic Auth or a
+// signed session cookie from LoginHandler).
+func mountAdminRoutes(rt *router.Router, cfg *config.Config) {
+	if !cfg.AdminEnabled() {
+		log.Println("ADMIN_USER/ADMIN_PASSWORD not set, admin endpoints disabled")
+		return
+	}
+
+	adminAuth := router.Middleware(func(next http.Handler) http.Handler {
+		return handlers.RequireAdmin(cfg, next)
+	})
+
+	adminRouter := handlers.NewAdminRouter()
+	prefix := fmt.Sprintf("/%s/admin/", cfg.SecretKey)
+	rt.Handle(prefix, http.StripPrefix(prefix[:len(prefix)-1], adminRouter), adminAuth)
+}
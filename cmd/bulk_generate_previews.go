@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"video-gallery/pkg/config"
+	"video-gallery/pkg/services"
+)
+
+// Command options
+var (
+	previewWorkers       int
+	previewFormat        string
+	previewSegments      int
+	previewSegmentSecs   float64
+	previewWidth         int
+	previewHeight        int
+	previewForceGenerate bool
+)
+
+// newBulkGeneratePreviewsCmd creates a new command that generates
+// hover-preview clips for every video missing one, through the
+// services-layer worker pool, rendering live progress as a terminal
+// progress bar (reusing the same bulkProgressBar as bulk-generate-thumbnails).
+func newBulkGeneratePreviewsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bulk-generate-previews",
+		Short: "Generate hover-preview clips for every video missing one, in parallel",
+		Long:  `Scans the bucket and generates a short animated WebP or muted MP4 preview clip for every video that doesn't already have one (or all of them, with --force), sampling segments across the video's duration and pipelining downloads, ffmpeg extraction, and uploads across a worker pool.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			bucketName := os.Getenv("BUCKET_NAME")
+			if bucketName == "" {
+				log.Fatalf("BUCKET_NAME environment variable not set")
+			}
+
+			svc := services.NewService(&config.Config{BucketName: bucketName})
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			bar := newBulkProgressBar()
+
+			previewOpts := services.PreviewOptions{
+				Format:          previewFormat,
+				SegmentCount:    previewSegments,
+				SegmentDuration: previewSegmentSecs,
+				Width:           previewWidth,
+				Height:          previewHeight,
+			}
+			opts := services.BulkOptions{Workers: previewWorkers, Force: previewForceGenerate}
+			processed, failed, err := svc.BulkGeneratePreviewClipsWithOptions(ctx, previewOpts, opts, bar.update)
+			bar.finish()
+
+			if ctx.Err() != nil {
+				fmt.Println("Interrupted - in-flight temp files were cleaned up")
+			} else if err != nil {
+				log.Fatalf("Bulk preview generation failed: %v", err)
+			}
+
+			fmt.Printf("Processed: %d, Failed: %d\n", processed, failed)
+		},
+	}
+
+	cmd.Flags().IntVarP(&previewWorkers, "workers", "w", runtime.NumCPU(), "Number of videos to process concurrently")
+	cmd.Flags().StringVarP(&previewFormat, "format", "F", services.DefaultPreviewOptions.Format, "Preview container: webp (animated) or mp4 (short muted clip)")
+	cmd.Flags().IntVar(&previewSegments, "segments", services.DefaultPreviewOptions.SegmentCount, "Number of segments sampled across the video")
+	cmd.Flags().Float64Var(&previewSegmentSecs, "segment-seconds", services.DefaultPreviewOptions.SegmentDuration, "Duration of each sampled segment, in seconds")
+	cmd.Flags().IntVar(&previewWidth, "width", services.DefaultPreviewOptions.Width, "Max preview width")
+	cmd.Flags().IntVar(&previewHeight, "height", services.DefaultPreviewOptions.Height, "Max preview height")
+	cmd.Flags().BoolVarP(&previewForceGenerate, "force", "f", false, "Regenerate previews that already exist")
+
+	return cmd
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"video-gallery/pkg/config"
+	"video-gallery/pkg/services"
+)
+
+// Command options
+var (
+	bulkWorkers int
+	bulkTimeMs  int
+	bulkForce   bool
+)
+
+// newBulkGenerateThumbnailsCmd creates a new command that generates
+// thumbnails for every video missing one through the services-layer worker
+// pool, rendering live progress as a terminal progress bar.
+func newBulkGenerateThumbnailsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bulk-generate-thumbnails",
+		Short: "Generate thumbnails for every video missing one, in parallel",
+		Long:  `Scans the bucket and generates thumbnails for every video that doesn't already have one (or all of them, with --force), pipelining downloads, ffmpeg extraction, and uploads across a worker pool.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			bucketName := os.Getenv("BUCKET_NAME")
+			if bucketName == "" {
+				log.Fatalf("BUCKET_NAME environment variable not set")
+			}
+
+			svc := services.NewService(&config.Config{BucketName: bucketName})
+
+			// Cancelling on SIGINT lets in-flight workers clean up their
+			// temp files instead of leaving them orphaned
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			bar := newBulkProgressBar()
+
+			opts := services.BulkOptions{Workers: bulkWorkers, TimeMs: bulkTimeMs, Force: bulkForce}
+			processed, failed, err := svc.BulkGenerateThumbnailsWithOptions(ctx, opts, bar.update)
+			bar.finish()
+
+			if ctx.Err() != nil {
+				fmt.Println("Interrupted - in-flight temp files were cleaned up")
+			} else if err != nil {
+				log.Fatalf("Bulk generation failed: %v", err)
+			}
+
+			fmt.Printf("Processed: %d, Failed: %d\n", processed, failed)
+		},
+	}
+
+	cmd.Flags().IntVarP(&bulkWorkers, "workers", "w", runtime.NumCPU(), "Number of videos to process concurrently")
+	cmd.Flags().IntVarP(&bulkTimeMs, "time", "t", 1000, "Time in milliseconds where to extract the frame")
+	cmd.Flags().BoolVarP(&bulkForce, "force", "f", false, "Regenerate thumbnails that already exist")
+
+	return cmd
+}
+
+// bulkProgressBar renders services.BulkProgress updates as a single
+// self-overwriting terminal line (cheggaaa/pb style), refreshed on a ticker
+// rather than on every update so a fast run doesn't flood the terminal.
+type bulkProgressBar struct {
+	mu     sync.Mutex
+	latest services.BulkProgress
+	start  time.Time
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newBulkProgressBar() *bulkProgressBar {
+	bar := &bulkProgressBar{start: time.Now(), done: make(chan struct{})}
+	go bar.run()
+	return bar
+}
+
+func (b *bulkProgressBar) update(p services.BulkProgress) {
+	b.mu.Lock()
+	b.latest = p
+	b.mu.Unlock()
+}
+
+func (b *bulkProgressBar) run() {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.render()
+		case <-b.done:
+			return
+		}
+	}
+}
+
+func (b *bulkProgressBar) render() {
+	b.mu.Lock()
+	p := b.latest
+	b.mu.Unlock()
+
+	elapsed := time.Since(b.start).Seconds()
+	var speed float64
+	if elapsed > 0 {
+		speed = float64(p.Completed+p.Failed) / elapsed
+	}
+
+	fmt.Printf("\r\033[K[%d/%d] %s: %s (%.2f/s, %d failed)",
+		p.Completed+p.Failed, p.Total, p.Stage, truncateMiddle(p.CurrentFile, 50), speed, p.Failed)
+}
+
+// finish stops the ticker, renders one last time, and moves to a fresh line
+// so the summary printed after it doesn't land on top of the bar.
+func (b *bulkProgressBar) finish() {
+	b.once.Do(func() {
+		close(b.done)
+		b.render()
+		fmt.Println()
+	})
+}
+
+// truncateMiddle keeps a path readable on a fixed-width progress line by
+// collapsing its middle into an ellipsis when it's longer than max.
+func truncateMiddle(path string, max int) string {
+	if len(path) <= max {
+		return path
+	}
+	half := (max - 3) / 2
+	return path[:half] + "..." + path[len(path)-half:]
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"video-gallery/pkg/services"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// Command options
+var (
+	forceMetadata bool
+)
+
+// newGenerateMetadataCmd creates a new command for backfilling rich video
+// metadata (duration, dimensions, fps, bitrate, codecs) onto thumbnails
+func newGenerateMetadataCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate-metadata",
+		Short: "Backfill technical video metadata onto existing thumbnails",
+		Long:  `Run ffprobe against each video whose thumbnail already exists and persist the resulting metadata (duration, dimensions, fps, bitrate, codecs) as custom metadata on the thumbnail object, without regenerating the thumbnail image itself.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := checkFFmpeg(); err != nil {
+				log.Fatalf("FFmpeg is required but not found: %v", err)
+			}
+
+			bucketName := os.Getenv("BUCKET_NAME")
+			if bucketName == "" {
+				log.Fatalf("BUCKET_NAME environment variable not set")
+			}
+
+			if err := os.MkdirAll(outputDir, 0755); err != nil {
+				log.Fatalf("Failed to create output directory: %v", err)
+			}
+
+			generateMetadata(bucketName)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&forceMetadata, "force", "f", false, "Re-probe videos that already have stored metadata")
+
+	return cmd
+}
+
+// generateMetadata scans the bucket for videos with an existing thumbnail
+// and backfills technical metadata onto that thumbnail object
+func generateMetadata(bucketName string) {
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		log.Fatalf("Failed to create storage client: %v", err)
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			log.Printf("Warning: error closing storage client: %v", err)
+		}
+	}()
+
+	bucket := client.Bucket(bucketName)
+
+	videoExtensions := []string{".mp4", ".m4v", ".webm", ".mov", ".avi"}
+	imageExtensions := []string{".jpg", ".jpeg", ".png"}
+
+	// First pass: find all thumbnail objects, keyed by base path
+	thumbnails := make(map[string]*storage.ObjectAttrs)
+	it := bucket.Objects(ctx, nil)
+	for {
+		obj, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Printf("Error iterating objects: %v", err)
+			continue
+		}
+
+		parts := strings.Split(obj.Name, "/")
+		if len(parts) != 3 || parts[2] == "" {
+			continue
+		}
+
+		for _, ext := range imageExtensions {
+			if strings.HasSuffix(parts[2], ext) {
+				thumbnails[obj.Name[:len(obj.Name)-len(filepath.Ext(obj.Name))]] = obj
+				break
+			}
+		}
+	}
+
+	// Second pass: probe each video that has a matching thumbnail
+	it = bucket.Objects(ctx, nil)
+	probed := 0
+	skipped := 0
+
+	for {
+		obj, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Printf("Error iterating objects: %v", err)
+			continue
+		}
+
+		parts := strings.Split(obj.Name, "/")
+		if len(parts) != 3 || parts[2] == "" {
+			continue
+		}
+		filename := parts[2]
+
+		isVideo := false
+		for _, ext := range videoExtensions {
+			if strings.HasSuffix(filename, ext) {
+				isVideo = true
+				break
+			}
+		}
+		if !isVideo {
+			continue
+		}
+
+		basePath := obj.Name[:len(obj.Name)-len(filepath.Ext(obj.Name))]
+		thumbnail, ok := thumbnails[basePath]
+		if !ok {
+			continue
+		}
+
+		if !forceMetadata && thumbnail.Metadata["info-duration"] != "" {
+			skipped++
+			continue
+		}
+
+		fmt.Printf("Probing metadata for: %s\n", obj.Name)
+
+		tmpVideoPath := filepath.Join(outputDir, getSafeFilename(obj.Name))
+		if err := downloadFile(ctx, bucket, obj.Name, tmpVideoPath); err != nil {
+			log.Printf("  Error downloading %s: %v", obj.Name, err)
+			continue
+		}
+
+		info, err := services.ProbeVideo(tmpVideoPath)
+		os.Remove(tmpVideoPath)
+		if err != nil {
+			log.Printf("  Error probing %s: %v", obj.Name, err)
+			continue
+		}
+
+		metadata := thumbnail.Metadata
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		for key, value := range services.EncodeVideoInfoMetadata(info) {
+			metadata[key] = value
+		}
+
+		if _, err := bucket.Object(thumbnail.Name).Update(ctx, storage.ObjectAttrsToUpdate{Metadata: metadata}); err != nil {
+			log.Printf("  Error updating metadata for %s: %v", thumbnail.Name, err)
+			continue
+		}
+
+		probed++
+	}
+
+	fmt.Printf("\nSummary:\n")
+	fmt.Printf("  Probed: %d\n", probed)
+	fmt.Printf("  Already had metadata: %d\n", skipped)
+}
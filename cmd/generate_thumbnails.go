@@ -3,19 +3,22 @@ package cmd
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
-	"image"
-	_ "image/jpeg"
-	_ "image/png"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -28,10 +31,26 @@ import (
 
 // Command options
 var (
-	outputDir       string
-	forceRegenerate bool
-	frameTimeMs     int // Time in milliseconds where to extract the frame
-	maxSizeMB       int // Maximum video size in MB to process
+	outputDir              string
+	forceRegenerate        bool
+	frameTimeMs            int    // Time in milliseconds where to extract the frame
+	maxSizeMB              int    // Maximum video size in MB to process
+	smartFrames            bool   // Use scene-aware frame selection instead of a fixed timestamp
+	thumbnailFormat        string // Output format: jpeg, webp, or avif
+	thumbnailWidth         int    // Box-fit width for the generated thumbnail
+	thumbnailHeight        int    // Box-fit height for the generated thumbnail
+	animatedPreview        bool   // Also generate a short looping animated WebP preview
+	workers                int    // Number of videos to process concurrently
+	maxConcurrentDownloads int    // Cap on simultaneous bucket downloads
+	maxConcurrentFfmpeg    int    // Cap on simultaneous ffmpeg invocations
+)
+
+// animatedPreviewFrameCount and animatedPreviewFps control the animated
+// WebP preview produced when --animated is set: 24 frames spread across the
+// video's duration, played back at roughly 8 frames per second.
+const (
+	animatedPreviewFrameCount = 24
+	animatedPreviewFps        = 8
 )
 
 // newGenerateThumbnailsCmd creates a new command for generating thumbnails for videos
@@ -64,10 +83,29 @@ func newGenerateThumbnailsCmd() *cobra.Command {
 	cmd.Flags().BoolVarP(&forceRegenerate, "force", "f", false, "Force regeneration of all thumbnails, even if they exist")
 	cmd.Flags().IntVarP(&frameTimeMs, "time", "t", 1000, "Time in milliseconds where to extract the thumbnail frame")
 	cmd.Flags().IntVarP(&maxSizeMB, "max-size", "m", 1024, "Maximum video size in MB to process (0 means no limit)")
+	cmd.Flags().BoolVar(&smartFrames, "smart-frames", false, "Pick a representative frame via scene detection and quality scoring instead of a fixed timestamp")
+	cmd.Flags().StringVar(&thumbnailFormat, "format", "jpeg", "Thumbnail output format: jpeg, webp, or avif")
+	cmd.Flags().IntVar(&thumbnailWidth, "width", 320, "Box-fit width for the generated thumbnail")
+	cmd.Flags().IntVar(&thumbnailHeight, "height", 200, "Box-fit height for the generated thumbnail")
+	cmd.Flags().BoolVar(&animatedPreview, "animated", false, "Also generate a short looping animated WebP preview for hover playback")
+	cmd.Flags().IntVar(&workers, "workers", runtime.NumCPU(), "Number of videos to process concurrently")
+	cmd.Flags().IntVar(&maxConcurrentDownloads, "max-concurrent-downloads", 4, "Maximum number of simultaneous bucket downloads across all workers")
+	cmd.Flags().IntVar(&maxConcurrentFfmpeg, "max-concurrent-ffmpeg", runtime.NumCPU(), "Maximum number of simultaneous ffmpeg invocations across all workers")
 
 	return cmd
 }
 
+// thumbnailWorkItem describes a single video found while scanning the
+// bucket that needs a thumbnail (re)generated
+type thumbnailWorkItem struct {
+	category      string
+	gallery       string
+	filename      string
+	videoAttrs    *storage.ObjectAttrs
+	basePath      string
+	thumbnailPath string
+}
+
 // generateThumbnails creates thumbnails for videos that don't have them
 func generateThumbnails() {
 	// Check if ffmpeg is installed
@@ -80,7 +118,11 @@ func generateThumbnails() {
 		log.Fatalf("Failed to create output directory: %v", err)
 	}
 
-	ctx := context.Background()
+	// Cancel in-flight work cleanly on SIGINT/SIGTERM so workers can clean
+	// up their temp files instead of leaving them orphaned
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	client, err := storage.NewClient(ctx)
 	if err != nil {
 		log.Fatalf("Failed to create storage client: %v", err)
@@ -104,8 +146,10 @@ func generateThumbnails() {
 	videoExtensions := []string{".mp4", ".m4v", ".webm", ".mov", ".avi"}
 	imageExtensions := []string{".jpg", ".jpeg", ".png"}
 
-	// Map to track which videos have thumbnails
-	thumbnailsMap := make(map[string]bool)
+	// Map from base path (without extension) to the existing thumbnail's
+	// attributes, so we can compare stored source hashes and skip
+	// regenerating thumbnails for videos that haven't changed
+	thumbnailsMap := make(map[string]*storage.ObjectAttrs)
 
 	// First pass: find all thumbnails
 	it := bucket.Objects(ctx, nil)
@@ -138,16 +182,16 @@ func generateThumbnails() {
 
 		if isImage {
 			// Store the base path without extension
-			thumbnailsMap[obj.Name[:len(obj.Name)-len(filepath.Ext(obj.Name))]] = true
+			thumbnailsMap[obj.Name[:len(obj.Name)-len(filepath.Ext(obj.Name))]] = obj
 		}
 	}
 
-	// Second pass: find all videos and check if they need thumbnails
+	// Second pass: find all videos and collect the ones that need a
+	// thumbnail (re)generated into a work list for the worker pool below
 	it = bucket.Objects(ctx, nil)
 
+	var workItems []thumbnailWorkItem
 	totalVideos := 0
-	totalProcessed := 0
-	missingThumbnails := 0
 
 	for {
 		obj, err := it.Next()
@@ -188,92 +232,198 @@ func generateThumbnails() {
 		// Get base name without extension
 		basePath := videoPath[:len(videoPath)-len(filepath.Ext(videoPath))]
 
-		// Check if thumbnail exists
-		thumbnailNeeded := !thumbnailsMap[basePath] || forceRegenerate
+		// Check if thumbnail exists and still matches the video's content
+		existingThumbnail := thumbnailsMap[basePath]
+		if !forceRegenerate && !needsRegeneration(obj, existingThumbnail) {
+			continue
+		}
+
+		// Check file size before downloading if max size limit is set
+		if maxSizeMB > 0 {
+			videoSizeMB := obj.Size / (1024 * 1024)
+			if videoSizeMB > int64(maxSizeMB) {
+				fmt.Printf("Skipping video %s: size %d MB exceeds limit of %d MB\n",
+					filename, videoSizeMB, maxSizeMB)
+				continue
+			}
+		}
+
+		workItems = append(workItems, thumbnailWorkItem{
+			category:      category,
+			gallery:       gallery,
+			filename:      filename,
+			videoAttrs:    obj,
+			basePath:      basePath,
+			thumbnailPath: basePath + extensionForFormat(thumbnailFormat),
+		})
+	}
 
-		if thumbnailNeeded {
-			missingThumbnails++
-			fmt.Printf("Gallery: %s/%s\n", category, gallery)
-			fmt.Printf("  Generating thumbnail for: %s\n", filename)
+	fmt.Printf("Found %d videos needing thumbnails out of %d total (%d workers, max %d downloads / %d ffmpeg jobs at once)\n",
+		len(workItems), totalVideos, workers, maxConcurrentDownloads, maxConcurrentFfmpeg)
+
+	// A single channel feeds all workers; closing it once every item has
+	// been sent (or the context is cancelled) signals workers to exit
+	items := make(chan thumbnailWorkItem)
+	go func() {
+		defer close(items)
+		for _, item := range workItems {
+			select {
+			case items <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
-			thumbnailPath := basePath + ".jpg"
+	// Semaphores bound total concurrent downloads/ffmpeg invocations across
+	// all workers, independent of how many workers are running
+	downloadSem := make(chan struct{}, maxConcurrentDownloads)
+	ffmpegSem := make(chan struct{}, maxConcurrentFfmpeg)
 
-			// Check file size before downloading if max size limit is set
-			if maxSizeMB > 0 {
-				videoSizeMB := obj.Size / (1024 * 1024)
+	var (
+		statsMu   sync.Mutex
+		processed int
+		failed    int
+	)
 
-				if videoSizeMB > int64(maxSizeMB) {
-					fmt.Printf("    Skipping video %s: size %d MB exceeds limit of %d MB\n",
-						filename, videoSizeMB, maxSizeMB)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		workerID := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Each worker gets its own temp subdirectory so concurrent
+			// downloads/conversions never collide on filenames
+			workerDir := filepath.Join(outputDir, fmt.Sprintf("worker-%d", workerID))
+			if err := os.MkdirAll(workerDir, 0755); err != nil {
+				log.Printf("Worker %d: failed to create temp dir: %v", workerID, err)
+				return
+			}
+			defer os.RemoveAll(workerDir)
+
+			for item := range items {
+				if err := processThumbnailWorkItem(ctx, bucket, workerDir, item, downloadSem, ffmpegSem); err != nil {
+					fmt.Printf("[worker %d] Gallery: %s/%s: %s: %v\n", workerID, item.category, item.gallery, item.filename, err)
+					statsMu.Lock()
+					failed++
+					statsMu.Unlock()
 					continue
 				}
+				fmt.Printf("[worker %d] Created thumbnail: %s\n", workerID, item.thumbnailPath)
+				statsMu.Lock()
+				processed++
+				statsMu.Unlock()
 			}
+		}()
+	}
 
-			// Generate safe filenames for local storage
-			videoBaseName := getSafeFilename(videoPath)
-			thumbnailBaseName := getSafeFilename(thumbnailPath)
+	wg.Wait()
 
-			// Download video to temp location with safe filename
-			tmpVideoPath := filepath.Join(outputDir, videoBaseName)
-			if err := downloadFile(ctx, bucket, videoPath, tmpVideoPath); err != nil {
-				fmt.Printf("    Error downloading video: %v\n", err)
-				continue
-			}
+	if ctx.Err() != nil {
+		fmt.Println("Interrupted - any in-flight work was cleaned up via its worker's temp directory")
+	}
 
-			// Create thumbnail using FFmpeg with safe filename
-			tmpThumbnailPath := filepath.Join(outputDir, thumbnailBaseName)
-			if err := createThumbnailWithFFmpeg(tmpVideoPath, tmpThumbnailPath); err != nil {
-				fmt.Printf("    Error creating thumbnail: %v\n", err)
-				// Clean up video file
-				if err := os.Remove(tmpVideoPath); err != nil {
-					log.Printf("Warning: failed to remove temp file %s: %v", tmpVideoPath, err)
-				}
-				continue
-			}
+	fmt.Printf("\nSummary:\n")
+	fmt.Printf("  Total videos: %d\n", totalVideos)
+	fmt.Printf("  Videos needing thumbnails: %d\n", len(workItems))
+	fmt.Printf("  Thumbnails successfully generated: %d\n", processed)
+	fmt.Printf("  Failed: %d\n", failed)
+}
 
-			// Validate the thumbnail isn't just a solid color
-			if err := validateThumbnail(tmpThumbnailPath); err != nil {
-				fmt.Printf("    Warning: thumbnail validation failed: %v\n", err)
-				// Clean up files
-				if err := os.Remove(tmpVideoPath); err != nil {
-					log.Printf("Warning: failed to remove temp file %s: %v", tmpVideoPath, err)
-				}
-				if err := os.Remove(tmpThumbnailPath); err != nil {
-					log.Printf("Warning: failed to remove temp file %s: %v", tmpThumbnailPath, err)
-				}
-				continue
-			}
+// processThumbnailWorkItem downloads a single video, extracts and validates
+// a candidate frame, converts it to the requested output format, uploads it
+// (plus an optional animated preview), and cleans up its own temp files -
+// all scoped to workerDir so concurrent workers never collide
+func processThumbnailWorkItem(ctx context.Context, bucket *storage.BucketHandle, workerDir string, item thumbnailWorkItem, downloadSem, ffmpegSem chan struct{}) error {
+	videoBaseName := getSafeFilename(item.videoAttrs.Name)
+	candidatePath := filepath.Join(workerDir, videoBaseName+".candidate.jpg")
+
+	// Scene-aware frame selection and animated previews both seek around
+	// across the whole video, so they still need a full local copy. The
+	// common case - one frame at a fixed timestamp - can stream straight
+	// off the bucket (or a couple of ranged reads for a trailing-moov MP4)
+	// without ever writing the source video to local disk.
+	needsLocalVideo := smartFrames || animatedPreview
+
+	var tmpVideoPath string
+	if needsLocalVideo {
+		tmpVideoPath = filepath.Join(workerDir, videoBaseName)
+
+		downloadSem <- struct{}{}
+		err := downloadFile(ctx, bucket, item.videoAttrs.Name, tmpVideoPath)
+		<-downloadSem
+		if err != nil {
+			return fmt.Errorf("downloading video: %v", err)
+		}
+		defer os.Remove(tmpVideoPath)
 
-			// Upload thumbnail to bucket
-			if err := uploadFile(ctx, bucket, tmpThumbnailPath, thumbnailPath); err != nil {
-				fmt.Printf("    Error uploading thumbnail: %v\n", err)
-				// Clean up files
-				if err := os.Remove(tmpVideoPath); err != nil {
-					log.Printf("Warning: failed to remove temp file %s: %v", tmpVideoPath, err)
-				}
-				if err := os.Remove(tmpThumbnailPath); err != nil {
-					log.Printf("Warning: failed to remove temp file %s: %v", tmpThumbnailPath, err)
-				}
-				continue
-			}
+		createThumbnail := createThumbnailWithFFmpeg
+		if smartFrames {
+			createThumbnail = createThumbnailSmart
+		}
 
-			fmt.Printf("    Created thumbnail: %s\n", thumbnailPath)
-			totalProcessed++
+		ffmpegSem <- struct{}{}
+		err = createThumbnail(tmpVideoPath, candidatePath)
+		<-ffmpegSem
+		if err != nil {
+			return fmt.Errorf("creating thumbnail: %v", err)
+		}
+	} else {
+		// The download and the ffmpeg decode happen concurrently on the
+		// streamed path (ffmpeg reads as the bucket reader fills the pipe),
+		// so hold both semaphores for the duration of the call
+		downloadSem <- struct{}{}
+		ffmpegSem <- struct{}{}
+		err := createThumbnailFromBucket(ctx, bucket, item.videoAttrs.Name, candidatePath, workerDir)
+		<-ffmpegSem
+		<-downloadSem
+		if err != nil {
+			return fmt.Errorf("creating thumbnail: %v", err)
+		}
+	}
+	defer os.Remove(candidatePath)
 
-			// Clean up temporary files
-			if err := os.Remove(tmpVideoPath); err != nil {
-				log.Printf("Warning: failed to remove temp file %s: %v", tmpVideoPath, err)
-			}
-			if err := os.Remove(tmpThumbnailPath); err != nil {
-				log.Printf("Warning: failed to remove temp file %s: %v", tmpThumbnailPath, err)
+	// Validate the thumbnail isn't just a solid color
+	if err := validateThumbnail(candidatePath); err != nil {
+		return fmt.Errorf("thumbnail validation failed: %v", err)
+	}
+
+	// Convert the candidate frame into the requested output format and size
+	thumbnailBaseName := getSafeFilename(item.thumbnailPath)
+	tmpThumbnailPath := filepath.Join(workerDir, thumbnailBaseName)
+	if err := convertThumbnail(candidatePath, tmpThumbnailPath, thumbnailFormat, thumbnailWidth, thumbnailHeight); err != nil {
+		return fmt.Errorf("converting thumbnail: %v", err)
+	}
+	defer os.Remove(tmpThumbnailPath)
+
+	// Upload thumbnail to bucket, along with its blurhash placeholder and
+	// source content-hash cache keys
+	if err := uploadThumbnailWithBlurhash(ctx, bucket, tmpThumbnailPath, item.thumbnailPath, contentTypeForFormat(thumbnailFormat), sourceMetadataFor(item.videoAttrs)); err != nil {
+		return fmt.Errorf("uploading thumbnail: %v", err)
+	}
+
+	// Optionally generate a short looping animated WebP preview for hover
+	// playback, uploaded alongside the static thumbnail
+	if animatedPreview {
+		animPath := item.basePath + ".anim.webp"
+		tmpAnimPath := filepath.Join(workerDir, thumbnailBaseName+".anim.webp")
+
+		ffmpegSem <- struct{}{}
+		err := createAnimatedPreview(tmpVideoPath, tmpAnimPath, thumbnailWidth, thumbnailHeight)
+		<-ffmpegSem
+
+		if err != nil {
+			fmt.Printf("    Warning: failed to create animated preview for %s: %v\n", item.filename, err)
+		} else {
+			if err := uploadFileWithMetadata(ctx, bucket, tmpAnimPath, animPath, "image/webp", nil); err != nil {
+				fmt.Printf("    Error uploading animated preview for %s: %v\n", item.filename, err)
 			}
+			os.Remove(tmpAnimPath)
 		}
 	}
 
-	fmt.Printf("\nSummary:\n")
-	fmt.Printf("  Total videos: %d\n", totalVideos)
-	fmt.Printf("  Videos without thumbnails: %d\n", missingThumbnails)
-	fmt.Printf("  Thumbnails successfully generated: %d\n", totalProcessed)
+	return nil
 }
 
 // getSafeFilename creates a safe filename from a URL by:
@@ -400,6 +550,297 @@ func createThumbnailWithFFmpeg(videoPath, thumbnailPath string) error {
 	return nil
 }
 
+// streamableContainers are extensions ffmpeg can demux straight off a pipe
+// without ever needing to seek backward. MP4/M4V/MOV are handled separately
+// since only a "front moov" layout is actually streamable.
+var streamableContainers = map[string]bool{
+	".mkv":  true,
+	".webm": true,
+}
+
+const (
+	// mp4BoxHeaderSize is the size of a standard ISO base media box header:
+	// a 4-byte big-endian size followed by a 4-byte type
+	mp4BoxHeaderSize = 8
+	// moovProbeBytes is how far into an MP4 we scan box headers looking for
+	// moov before giving up and assuming it trails the file
+	moovProbeBytes = 256 * 1024
+	// mp4HeadBytes is how much of the front of a trailing-moov MP4 is
+	// downloaded - enough to hold the early frames the default frameTimeMs
+	// normally targets
+	mp4HeadBytes = 8 * 1024 * 1024
+	// mp4TailBytes is how much of the end of a trailing-moov MP4 is
+	// downloaded, which is normally enough to contain the whole moov atom
+	mp4TailBytes = 4 * 1024 * 1024
+)
+
+// createThumbnailFromBucket extracts a thumbnail frame for src straight from
+// the bucket, avoiding a full download to local disk where possible: WebM/MKV
+// and front-moov MP4s are piped into ffmpeg via stdin, back-moov MP4s fall
+// back to a couple of ranged reads, and anything else falls back to a plain
+// download.
+func createThumbnailFromBucket(ctx context.Context, bucket *storage.BucketHandle, src, thumbnailPath, workerDir string) error {
+	ext := strings.ToLower(filepath.Ext(src))
+
+	if streamableContainers[ext] {
+		return createThumbnailStreamed(ctx, bucket, src, thumbnailPath)
+	}
+
+	if ext == ".mp4" || ext == ".m4v" || ext == ".mov" {
+		if front, err := mp4MoovAtFront(ctx, bucket, src); err == nil && front {
+			return createThumbnailStreamed(ctx, bucket, src, thumbnailPath)
+		}
+		return createThumbnailFromRanges(ctx, bucket, src, thumbnailPath, workerDir)
+	}
+
+	tmpVideoPath := filepath.Join(workerDir, getSafeFilename(src))
+	if err := downloadFile(ctx, bucket, src, tmpVideoPath); err != nil {
+		return fmt.Errorf("downloading video: %v", err)
+	}
+	defer os.Remove(tmpVideoPath)
+	return createThumbnailWithFFmpeg(tmpVideoPath, thumbnailPath)
+}
+
+// createThumbnailStreamed pipes the bucket object straight into ffmpeg's
+// stdin, so the source video is never written to local disk.
+func createThumbnailStreamed(ctx context.Context, bucket *storage.BucketHandle, src, thumbnailPath string) error {
+	reader, err := bucket.Object(strings.TrimPrefix(src, "/")).NewReader(ctx)
+	if err != nil {
+		return fmt.Errorf("Object(%q).NewReader: %v", src, err)
+	}
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Printf("Warning: error closing reader: %v", err)
+		}
+	}()
+
+	seconds := frameTimeMs / 1000
+	milliseconds := frameTimeMs % 1000
+	timeStr := fmt.Sprintf("00:00:%02d.%03d", seconds, milliseconds)
+
+	cmd := exec.CommandContext(ctx,
+		"ffmpeg",
+		"-ss", timeStr,
+		"-i", "pipe:0",
+		"-vf", "thumbnail",
+		"-frames:v", "1",
+		"-q:v", "2",
+		"-y",
+		thumbnailPath,
+	)
+	cmd.Stdin = reader
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg (streamed) failed: %v, stderr: %s", err, stderr.String())
+	}
+
+	return nil
+}
+
+// mp4MoovAtFront reports whether an MP4/MOV's moov atom appears before its
+// mdat atom, which is required for ffmpeg to demux it from a plain pipe
+// without seeking backward. It scans box headers from the front of the file
+// rather than downloading the whole thing.
+func mp4MoovAtFront(ctx context.Context, bucket *storage.BucketHandle, src string) (bool, error) {
+	reader, err := bucket.Object(strings.TrimPrefix(src, "/")).NewRangeReader(ctx, 0, moovProbeBytes)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	header := make([]byte, mp4BoxHeaderSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			// Ran out of probe bytes before finding moov or mdat
+			return false, nil
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[0:4]))
+		boxType := string(header[4:8])
+
+		switch boxType {
+		case "moov":
+			return true, nil
+		case "mdat":
+			return false, nil
+		}
+
+		if size < mp4BoxHeaderSize {
+			// Malformed box, or a 64-bit "largesize" box we don't bother
+			// parsing - safest to assume moov isn't in the probed window
+			return false, nil
+		}
+
+		if _, err := io.CopyN(io.Discard, reader, size-mp4BoxHeaderSize); err != nil {
+			return false, nil
+		}
+	}
+}
+
+// createThumbnailFromRanges handles MP4s whose moov atom trails their sample
+// data. ffmpeg can't demux a pipe without seeking back to moov, so instead
+// fetch the head (mdat, which holds the early frames the default frameTimeMs
+// targets) and the tail (moov) as separate ranged reads and reassemble them
+// into a sparse local file that ffmpeg can open directly - trading the full
+// download for two much smaller partial ones.
+func createThumbnailFromRanges(ctx context.Context, bucket *storage.BucketHandle, src, thumbnailPath, workerDir string) error {
+	objName := strings.TrimPrefix(src, "/")
+	obj := bucket.Object(objName)
+
+	attrs, err := obj.Attrs(ctx)
+	if err != nil {
+		return fmt.Errorf("Object(%q).Attrs: %v", objName, err)
+	}
+
+	head := int64(mp4HeadBytes)
+	if head > attrs.Size {
+		head = attrs.Size
+	}
+	tail := int64(mp4TailBytes)
+	if tail > attrs.Size {
+		tail = attrs.Size
+	}
+
+	sparsePath := filepath.Join(workerDir, getSafeFilename(objName)+".partial.mp4")
+	f, err := os.Create(sparsePath)
+	if err != nil {
+		return fmt.Errorf("os.Create: %v", err)
+	}
+	defer os.Remove(sparsePath)
+
+	if err := f.Truncate(attrs.Size); err != nil {
+		f.Close()
+		return fmt.Errorf("truncate sparse file: %v", err)
+	}
+
+	if err := copyRangeInto(ctx, obj, f, 0, head); err != nil {
+		f.Close()
+		return fmt.Errorf("fetching head range: %v", err)
+	}
+	if err := copyRangeInto(ctx, obj, f, attrs.Size-tail, tail); err != nil {
+		f.Close()
+		return fmt.Errorf("fetching tail range: %v", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing sparse file: %v", err)
+	}
+
+	if err := createThumbnailWithFFmpeg(sparsePath, thumbnailPath); err != nil {
+		return fmt.Errorf("frame at %dms may fall outside the downloaded head range: %v", frameTimeMs, err)
+	}
+	return nil
+}
+
+// copyRangeInto fetches a byte range from a GCS object and writes it into
+// dst at the matching absolute offset.
+func copyRangeInto(ctx context.Context, obj *storage.ObjectHandle, dst *os.File, offset, length int64) error {
+	reader, err := obj.NewRangeReader(ctx, offset, length)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if _, err := dst.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, reader); err != nil {
+		return err
+	}
+	return nil
+}
+
+// extensionForFormat returns the file extension used for a thumbnail output format
+func extensionForFormat(format string) string {
+	switch format {
+	case "webp":
+		return ".webp"
+	case "avif":
+		return ".avif"
+	default:
+		return ".jpg"
+	}
+}
+
+// contentTypeForFormat returns the Content-Type used for a thumbnail output format
+func contentTypeForFormat(format string) string {
+	switch format {
+	case "webp":
+		return "image/webp"
+	case "avif":
+		return "image/avif"
+	default:
+		return "image/jpeg"
+	}
+}
+
+// convertThumbnail scales the JPEG frame at srcPath to fit within width x
+// height (preserving aspect ratio) and re-encodes it as dstPath in the
+// requested format
+func convertThumbnail(srcPath, dstPath, format string, width, height int) error {
+	args := []string{
+		"-i", srcPath,
+		"-vf", fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease", width, height),
+	}
+
+	switch format {
+	case "webp":
+		args = append(args, "-c:v", "libwebp")
+	case "avif":
+		args = append(args, "-c:v", "libaom-av1", "-still-picture", "1")
+	}
+
+	args = append(args, "-y", dstPath)
+
+	cmd := exec.Command("ffmpeg", args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %v, stderr: %s", err, stderr.String())
+	}
+
+	return nil
+}
+
+// createAnimatedPreview samples animatedPreviewFrameCount frames spread
+// across the video's duration and encodes them as a looping animated WebP,
+// for gallery hover-preview playback
+func createAnimatedPreview(videoPath, outputPath string, width, height int) error {
+	duration, err := getVideoDuration(videoPath)
+	if err != nil {
+		return fmt.Errorf("failed to determine video duration: %v", err)
+	}
+	if duration <= 0 {
+		return fmt.Errorf("video has no measurable duration")
+	}
+
+	frameInterval := duration / float64(animatedPreviewFrameCount)
+
+	cmd := exec.Command(
+		"ffmpeg",
+		"-i", videoPath,
+		"-vf", fmt.Sprintf("fps=1/%f,scale=%d:%d:force_original_aspect_ratio=decrease", frameInterval, width, height),
+		"-c:v", "libwebp_anim",
+		"-loop", "0",
+		"-r", fmt.Sprintf("%d", animatedPreviewFps),
+		"-y",
+		outputPath,
+	)
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %v, stderr: %s", err, stderr.String())
+	}
+
+	return nil
+}
+
 // downloadFile downloads a file from GCS bucket to a local path
 // Handles both direct object paths and signed URLs
 func downloadFile(ctx context.Context, bucket *storage.BucketHandle, src, dst string) error {
@@ -600,8 +1041,15 @@ func formatSize(bytes int64) string {
 	}
 }
 
-// uploadFile uploads a file to GCS bucket
+// uploadFile uploads a file to GCS bucket as a JPEG
 func uploadFile(ctx context.Context, bucket *storage.BucketHandle, src, dst string) error {
+	return uploadFileWithMetadata(ctx, bucket, src, dst, "image/jpeg", nil)
+}
+
+// uploadFileWithMetadata uploads src to dst with the given Content-Type,
+// attaching metadata as GCS custom metadata on the resulting object (e.g. a
+// blurhash placeholder).
+func uploadFileWithMetadata(ctx context.Context, bucket *storage.BucketHandle, src, dst, contentType string, metadata map[string]string) error {
 	// Read the file data
 	data, err := os.ReadFile(src)
 	if err != nil {
@@ -621,7 +1069,8 @@ func uploadFile(ctx context.Context, bucket *storage.BucketHandle, src, dst stri
 
 	// Create a writer with appropriate content type
 	writer := bucket.Object(dst).NewWriter(ctx)
-	writer.ContentType = "image/jpeg"
+	writer.ContentType = contentType
+	writer.Metadata = metadata
 
 	// Write the file
 	if _, err := writer.Write(data); err != nil {
@@ -639,76 +1088,88 @@ func uploadFile(ctx context.Context, bucket *storage.BucketHandle, src, dst stri
 	return nil
 }
 
-// validateThumbnail checks if a thumbnail is valid (not a solid color)
-func validateThumbnail(thumbnailPath string) error {
-	// Open the image file
-	f, err := os.Open(thumbnailPath)
-	if err != nil {
-		return fmt.Errorf("failed to open thumbnail: %v", err)
+// uploadThumbnailWithBlurhash uploads the thumbnail at thumbnailPath to dst
+// with the given Content-Type, computing a blurhash placeholder and merging
+// it with extraMetadata (e.g. the source content-hash cache keys) as custom
+// metadata so GetVideos can surface it without re-downloading the image.
+// Formats the stdlib's image package can't decode (e.g. WebP, AVIF) simply
+// skip the blurhash.
+func uploadThumbnailWithBlurhash(ctx context.Context, bucket *storage.BucketHandle, thumbnailPath, dst, contentType string, extraMetadata map[string]string) error {
+	metadata := map[string]string{}
+	for key, value := range extraMetadata {
+		metadata[key] = value
 	}
-	defer f.Close()
-
-	// Decode the image
-	img, _, err := image.Decode(f)
-	if err != nil {
-		return fmt.Errorf("failed to decode thumbnail: %v", err)
+	if hash, err := services.ComputeBlurhash(thumbnailPath); err != nil {
+		log.Printf("Warning: failed to compute blurhash for %s: %v", thumbnailPath, err)
+	} else {
+		metadata["blurhash"] = hash
 	}
 
-	// Sample pixels to check for color variation
-	// We'll sample a grid of points across the image
-	bounds := img.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
+	return uploadFileWithMetadata(ctx, bucket, thumbnailPath, dst, contentType, metadata)
+}
 
-	// Sample 100 points (10x10 grid)
-	sampleSize := 10
-	stepX := width / sampleSize
-	stepY := height / sampleSize
+// thumbnailGeneratorVersion is bumped whenever the thumbnail generation
+// algorithm or its output changes in a way that should invalidate the
+// content-addressable cache below, forcing existing thumbnails to be
+// regenerated even though their source video hasn't changed.
+const thumbnailGeneratorVersion = "1"
+
+// Metadata keys used to cache, on the thumbnail object itself, the content
+// hash of the video it was generated from and the generator version that
+// produced it - so subsequent runs can skip regenerating unchanged videos
+// without downloading them.
+const (
+	metadataKeySourceCRC32C     = "x-goog-meta-source-crc32c"
+	metadataKeyGeneratorVersion = "x-goog-meta-generator-version"
+)
 
-	if stepX == 0 {
-		stepX = 1
+// sourceMetadataFor builds the cache metadata to attach to a thumbnail,
+// recording the source video's CRC32C checksum (available directly from GCS
+// object attributes, no download required) and the current generator
+// version.
+func sourceMetadataFor(videoAttrs *storage.ObjectAttrs) map[string]string {
+	return map[string]string{
+		metadataKeySourceCRC32C:     strconv.FormatUint(uint64(videoAttrs.CRC32C), 10),
+		metadataKeyGeneratorVersion: thumbnailGeneratorVersion,
 	}
-	if stepY == 0 {
-		stepY = 1
+}
+
+// needsRegeneration reports whether the video's thumbnail is missing or
+// stale: either there is no existing thumbnail, its stored source CRC32C no
+// longer matches the video's current checksum, or it was produced by an
+// older generator version.
+func needsRegeneration(videoAttrs, thumbnailAttrs *storage.ObjectAttrs) bool {
+	if thumbnailAttrs == nil {
+		return true
 	}
 
-	// Get the first pixel's color as reference
-	firstColor := img.At(bounds.Min.X, bounds.Min.Y)
-	r1, g1, b1, a1 := firstColor.RGBA()
+	expected := sourceMetadataFor(videoAttrs)
+	for key, value := range expected {
+		if thumbnailAttrs.Metadata[key] != value {
+			return true
+		}
+	}
 
-	// Check if all sampled pixels are the same color
-	differentPixels := 0
-	totalSamples := 0
+	return false
+}
 
-	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
-		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
-			totalSamples++
-			r2, g2, b2, a2 := img.At(x, y).RGBA()
+// validateThumbnail checks if a thumbnail is valid by computing its blurhash
+// and rejecting it if the decoded preview has too little variance (a solid
+// color, or close to it - e.g. a fade-in or logo screen).
+func validateThumbnail(thumbnailPath string) error {
+	hash, err := services.ComputeBlurhash(thumbnailPath)
+	if err != nil {
+		return fmt.Errorf("failed to compute blurhash: %v", err)
+	}
 
-			// If any color component differs by more than a small threshold, count it as different
-			// Using a threshold to account for compression artifacts
-			threshold := uint32(256) // About 1 unit difference in 8-bit color
-			if abs(int(r1)-int(r2)) > int(threshold) ||
-				abs(int(g1)-int(g2)) > int(threshold) ||
-				abs(int(b1)-int(b2)) > int(threshold) ||
-				abs(int(a1)-int(a2)) > int(threshold) {
-				differentPixels++
-			}
-		}
+	variance, err := blurhashVariance(hash)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate blurhash variance: %v", err)
 	}
 
-	// If less than 1% of pixels are different, consider it a solid color
-	if totalSamples > 0 && float64(differentPixels)/float64(totalSamples) < 0.01 {
-		return fmt.Errorf("thumbnail appears to be a solid color (only %d/%d sampled pixels differ)", differentPixels, totalSamples)
+	if variance < blurhashVarianceThreshold {
+		return fmt.Errorf("thumbnail appears to be a solid color (blurhash variance %.2f below threshold %.2f)", variance, blurhashVarianceThreshold)
 	}
 
 	return nil
 }
-
-// abs returns the absolute value of an integer
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
-}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"video-gallery/pkg/services"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// newRegenerateBlurhashesCmd creates a new command for backfilling blurhash
+// metadata on existing thumbnails
+func newRegenerateBlurhashesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "regenerate-blurhashes",
+		Short: "Backfill blurhash metadata on existing thumbnails",
+		Long:  `Iterate over existing thumbnails in the bucket and compute/store a blurhash placeholder for any that are missing one, without regenerating the thumbnail image itself.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			bucketName := os.Getenv("BUCKET_NAME")
+			if bucketName == "" {
+				log.Fatalf("BUCKET_NAME environment variable not set")
+			}
+			regenerateBlurhashes(bucketName)
+		},
+	}
+}
+
+// regenerateBlurhashes scans the bucket for thumbnail images missing a
+// blurhash attribute and backfills it
+func regenerateBlurhashes(bucketName string) {
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		log.Fatalf("Failed to create storage client: %v", err)
+	}
+	defer client.Close()
+
+	bucket := client.Bucket(bucketName)
+
+	outputDir := filepath.Join(os.TempDir(), "video-gallery-thumbnails")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		log.Fatalf("Failed to create output directory: %v", err)
+	}
+
+	imageExtensions := []string{".jpg", ".jpeg", ".png"}
+
+	it := bucket.Objects(ctx, nil)
+	updated := 0
+	skipped := 0
+
+	for {
+		obj, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Printf("Error iterating objects: %v", err)
+			continue
+		}
+
+		parts := strings.Split(obj.Name, "/")
+		if len(parts) != 3 || parts[2] == "" {
+			continue
+		}
+
+		isImage := false
+		for _, ext := range imageExtensions {
+			if strings.HasSuffix(parts[2], ext) {
+				isImage = true
+				break
+			}
+		}
+		if !isImage {
+			continue
+		}
+
+		if obj.Metadata["blurhash"] != "" {
+			skipped++
+			continue
+		}
+
+		fmt.Printf("Backfilling blurhash for: %s\n", obj.Name)
+
+		tmpPath := filepath.Join(outputDir, strings.ReplaceAll(obj.Name, "/", "_"))
+		if err := downloadThumbnail(ctx, bucket, obj.Name, tmpPath); err != nil {
+			log.Printf("  Error downloading %s: %v", obj.Name, err)
+			continue
+		}
+
+		hash, err := services.ComputeBlurhash(tmpPath)
+		os.Remove(tmpPath)
+		if err != nil {
+			log.Printf("  Error computing blurhash for %s: %v", obj.Name, err)
+			continue
+		}
+
+		metadata := obj.Metadata
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata["blurhash"] = hash
+
+		if _, err := bucket.Object(obj.Name).Update(ctx, storage.ObjectAttrsToUpdate{Metadata: metadata}); err != nil {
+			log.Printf("  Error updating metadata for %s: %v", obj.Name, err)
+			continue
+		}
+
+		updated++
+	}
+
+	fmt.Printf("\nSummary:\n")
+	fmt.Printf("  Updated: %d\n", updated)
+	fmt.Printf("  Already had blurhash: %d\n", skipped)
+}
+
+// downloadThumbnail copies a single object from the bucket to a local path
+func downloadThumbnail(ctx context.Context, bucket *storage.BucketHandle, src, dst string) error {
+	reader, err := bucket.Object(src).NewReader(ctx)
+	if err != nil {
+		return fmt.Errorf("Object(%q).NewReader: %v", src, err)
+	}
+	defer reader.Close()
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("os.Create: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.ReadFrom(reader); err != nil {
+		return fmt.Errorf("ReadFrom: %v", err)
+	}
+
+	return nil
+}
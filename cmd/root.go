@@ -34,6 +34,10 @@ stored in Google Cloud Storage. It can also serve these galleries via a web inte
 	rootCmd.AddCommand(newExportCmd())
 	rootCmd.AddCommand(newServeCmd())
 	rootCmd.AddCommand(newGenerateThumbnailsCmd())
+	rootCmd.AddCommand(newRegenerateBlurhashesCmd())
+	rootCmd.AddCommand(newGenerateMetadataCmd())
+	rootCmd.AddCommand(newBulkGenerateThumbnailsCmd())
+	rootCmd.AddCommand(newBulkGeneratePreviewsCmd())
 
 	return rootCmd
 }
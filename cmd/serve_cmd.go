@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 	"video-gallery/pkg/config"
 	"video-gallery/pkg/handlers"
+	"video-gallery/pkg/router"
 	"video-gallery/pkg/services"
 )
 
@@ -31,16 +32,61 @@ func newServeCmd() *cobra.Command {
 
 // serveWebsite runs the web server to serve the gallery content
 func serveWebsite(cfg *config.Config) {
-	// Use the original web server functionality
+	// Every route gets panic recovery, structured request logging, and
+	// gzip compression; auth is layered on top per-route below.
+	rt := router.New(router.Recover, router.Logging, router.Gzip)
+
 	fileServer := http.FileServer(http.Dir("./public"))
-	http.Handle("/", fileServer)
-	http.HandleFunc("/gallery/", handlers.PageHandler)
-	http.HandleFunc(fmt.Sprintf("/%s/index", cfg.SecretKey), handlers.GalleryHandler)
-	http.HandleFunc(fmt.Sprintf("/%s/feed", cfg.SecretKey), handlers.FeedHandler)
+	rt.Handle("/", fileServer)
+	rt.HandleFunc("/gallery/", handlers.PageHandler)
+	rt.HandleFunc("/show/", handlers.PageHandler)
+
+	// Every route under the secret-key prefix mutates or discloses gallery
+	// content, so - in addition to living under that prefix - each one is
+	// also gated behind RequireAdmin rather than trusting the URL segment
+	// alone as proof of authorization.
+	adminAuth := router.Middleware(func(next http.Handler) http.Handler {
+		return handlers.RequireAdmin(cfg, next)
+	})
+	rt.HandleFunc(fmt.Sprintf("GET /%s/index", cfg.SecretKey), handlers.GalleryHandler, adminAuth)
+	rt.HandleFunc(fmt.Sprintf("GET /%s/feed", cfg.SecretKey), handlers.FeedHandler, adminAuth)
+	rt.HandleFunc(fmt.Sprintf("GET /%s/status", cfg.SecretKey), handlers.StatusHandler, adminAuth)
+	rt.HandleFunc(fmt.Sprintf("GET /%s/thumbnail", cfg.SecretKey), handlers.ThumbnailHandler(cfg), adminAuth)
+
+	rt.HandleFunc(fmt.Sprintf("GET /%s/jobs", cfg.SecretKey), handlers.ListJobsHandler, adminAuth)
+	rt.HandleFunc(fmt.Sprintf("GET /%s/jobs/{id}/events", cfg.SecretKey), handlers.JobEventsHandler, adminAuth)
+	rt.HandleFunc(fmt.Sprintf("POST /%s/jobs/{id}/cancel", cfg.SecretKey), handlers.CancelJobHandler, adminAuth)
+	rt.HandleFunc(fmt.Sprintf("POST /%s/jobs/thumbnail", cfg.SecretKey), handlers.StartThumbnailJobHandler(cfg), adminAuth)
+	rt.HandleFunc(fmt.Sprintf("POST /%s/jobs/poster", cfg.SecretKey), handlers.StartPosterJobHandler(cfg), adminAuth)
+	rt.HandleFunc(fmt.Sprintf("POST /%s/jobs/bulk-thumbnail", cfg.SecretKey), handlers.StartBulkThumbnailJobHandler(cfg), adminAuth)
+
+	// /login exchanges admin credentials for a signed session cookie, so the
+	// browser doesn't need to resend Basic Auth on every admin request.
+	rt.HandleFunc("POST /login", handlers.LoginHandler(cfg))
+
+	// /preview/* serves the same gallery/feed content gated by a signed,
+	// shareable preview token instead of cfg.SecretKey, for sharing a
+	// gallery link without exposing the admin secret.
+	previewAuth := router.Middleware(func(next http.Handler) http.Handler {
+		return handlers.RequirePreview(cfg, next)
+	})
+	rt.HandleFunc("GET /preview/index", handlers.GalleryHandler, previewAuth)
+	rt.HandleFunc("GET /preview/feed", handlers.FeedHandler, previewAuth)
+
+	// /content/thumbnail is what GetVideos embeds as each Video.Thumbnail
+	// URL, gated by a signed content token rather than cfg.SecretKey so
+	// gallery/feed responses - including ones served through /preview/* -
+	// never disclose the admin secret.
+	contentAuth := router.Middleware(func(next http.Handler) http.Handler {
+		return handlers.RequireContent(cfg, next)
+	})
+	rt.HandleFunc("GET /content/thumbnail", handlers.ThumbnailHandler(cfg), contentAuth)
+
+	mountAdminRoutes(rt, cfg)
 
 	// Start server
 	cfg.PrintServerStartMessage()
-	if err := http.ListenAndServe(cfg.ServerAddress(), nil); err != nil {
+	if err := http.ListenAndServe(cfg.ServerAddress(), rt); err != nil {
 		log.Printf("Server error: %v", err)
 		os.Exit(1)
 	}
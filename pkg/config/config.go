@@ -4,13 +4,28 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	SecretKey  string
-	BucketName string
-	Port       string
+	SecretKey     string
+	BucketName    string
+	Port          string
+	AdminUser     string
+	AdminPassword string
+	PubSubTopic   string
+
+	// ThumbnailCachePath is the BoltDB file used to remember which
+	// thumbnails are already up to date with their source video, so bulk
+	// regeneration can skip unchanged ones. Empty means the cache falls
+	// back to a fixed path under os.TempDir().
+	ThumbnailCachePath string
+
+	// MaxUploadBytes caps how large a video services.UploadVideo will
+	// accept. Zero or negative means the services.MaxUploadSize default
+	// applies.
+	MaxUploadBytes int64
 }
 
 // ErrSecretKeyNotSet is returned when the SECRET_KEY environment variable is not set
@@ -36,13 +51,25 @@ func Load() (*Config, error) {
 		port = "8080"
 	}
 
+	maxUploadBytes, _ := strconv.ParseInt(os.Getenv("MAX_UPLOAD_SIZE_BYTES"), 10, 64)
+
 	return &Config{
-		SecretKey:  secretKey,
-		BucketName: bucketName,
-		Port:       port,
+		SecretKey:          secretKey,
+		BucketName:         bucketName,
+		Port:               port,
+		AdminUser:          os.Getenv("ADMIN_USER"),
+		AdminPassword:      os.Getenv("ADMIN_PASSWORD"),
+		PubSubTopic:        os.Getenv("PUBSUB_TOPIC"),
+		ThumbnailCachePath: os.Getenv("THUMBNAIL_CACHE_PATH"),
+		MaxUploadBytes:     maxUploadBytes,
 	}, nil
 }
 
+// AdminEnabled reports whether admin credentials have been configured
+func (c *Config) AdminEnabled() bool {
+	return c.AdminUser != "" && c.AdminPassword != ""
+}
+
 // ServerAddress returns the server address with port
 func (c *Config) ServerAddress() string {
 	return fmt.Sprintf(":%s", c.Port)
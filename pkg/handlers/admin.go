@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"video-gallery/pkg/config"
+	"video-gallery/pkg/router"
+	"video-gallery/pkg/services"
+)
+
+// missingCredentialDelay is added before responding to any request that
+// fails admin authentication (missing or wrong credentials), to blunt naive
+// brute-force scanning.
+const missingCredentialDelay = 500 * time.Millisecond
+
+// basicAuthMatches reports whether r carries HTTP Basic Auth credentials
+// matching the configured ADMIN_USER/ADMIN_PASSWORD, using a constant-time
+// comparison so response timing doesn't leak how much of the credential
+// matched.
+func basicAuthMatches(cfg *config.Config, r *http.Request) bool {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	userMatch := subtle.ConstantTimeCompare([]byte(user), []byte(cfg.AdminUser)) == 1
+	passMatch := subtle.ConstantTimeCompare([]byte(password), []byte(cfg.AdminPassword)) == 1
+	return userMatch && passMatch
+}
+
+// RequireAdminAuth wraps next with HTTP Basic Auth checked against the
+// configured ADMIN_USER/ADMIN_PASSWORD. Prefer RequireAdmin for
+// browser-facing routes, which also accepts a signed session cookie so the
+// browser doesn't have to resend credentials on every request.
+func RequireAdminAuth(cfg *config.Config, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !basicAuthMatches(cfg, r) {
+			time.Sleep(missingCredentialDelay)
+			w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// NewAdminRouter builds the CRUD admin router (upload/rename/delete),
+// unauthenticated - callers should wrap it with RequireAdmin or
+// RequireAdminAuth before mounting it.
+func NewAdminRouter() *router.Router {
+	rt := router.New()
+	rt.HandleFunc("POST /upload", UploadVideoHandler)
+	rt.HandleFunc("POST /rename", RenameVideoHandler)
+	rt.HandleFunc("POST /delete", DeleteVideoHandler)
+	rt.HandleFunc("DELETE /delete", DeleteVideoHandler)
+	return rt
+}
+
+// UploadVideoHandler accepts a multipart/form-data upload and hands the
+// video part to services.UploadVideo, which stages it to a temp file,
+// validates it, and uploads it to GCS. category and gallery are read from
+// the query string, since reading form values would consume the multipart
+// body before MultipartReader could stream it.
+func UploadVideoHandler(w http.ResponseWriter, r *http.Request) {
+	category := r.URL.Query().Get("category")
+	gallery := r.URL.Query().Get("gallery")
+	if category == "" || gallery == "" {
+		http.Error(w, "category and gallery are required", http.StatusBadRequest)
+		return
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "Invalid multipart request", http.StatusBadRequest)
+		return
+	}
+
+	var uploaded bool
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		log.Printf("Uploading video %s to %s/%s", part.FileName(), category, gallery)
+		if err := services.UploadVideo(category, gallery, part.FileName(), part); err != nil {
+			http.Error(w, fmt.Sprintf("upload failed: %v", err), http.StatusBadRequest)
+			return
+		}
+		uploaded = true
+		break
+	}
+
+	if !uploaded {
+		http.Error(w, "no file part found in request", http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// RenameVideoHandler moves a video (and its thumbnail) to a new filename
+// within the same category/gallery.
+func RenameVideoHandler(w http.ResponseWriter, r *http.Request) {
+	category := r.FormValue("category")
+	gallery := r.FormValue("gallery")
+	oldName := r.FormValue("oldName")
+	newName := r.FormValue("newName")
+	if category == "" || gallery == "" || oldName == "" || newName == "" {
+		http.Error(w, "category, gallery, oldName and newName are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := services.RenameVideo(category, gallery, oldName, newName); err != nil {
+		http.Error(w, fmt.Sprintf("rename failed: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// DeleteVideoHandler removes a video (and its thumbnail) from the bucket.
+// It's registered for both POST and DELETE (see NewAdminRouter).
+func DeleteVideoHandler(w http.ResponseWriter, r *http.Request) {
+	category := r.FormValue("category")
+	gallery := r.FormValue("gallery")
+	name := r.FormValue("name")
+	if category == "" || gallery == "" || name == "" {
+		http.Error(w, "category, gallery and name are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := services.DeleteVideo(category, gallery, name); err != nil {
+		http.Error(w, fmt.Sprintf("delete failed: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
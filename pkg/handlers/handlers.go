@@ -53,6 +53,20 @@ func PageHandler(w http.ResponseWriter, r *http.Request) {
 	// Get the path
 	path := r.URL.String()
 
+	if show, err := services.GetShow(path); err == nil {
+		log.Println("Generating Show Page: " + path)
+
+		template, err := pug.CompileFile("./views/show.pug", pug.Options{})
+		if err != nil {
+			panic(err)
+		}
+
+		if err := template.Execute(w, show); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	gallery, err := services.GetGallery(path)
 	if err != nil {
 		log.Println("Gallery not found: " + path)
@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"video-gallery/pkg/config"
+	"video-gallery/pkg/services"
+)
+
+// JobStatus is the lifecycle state of a background job.
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+// jobProgress is a single progress frame for a single-file job, also used as
+// the SSE payload.
+type jobProgress struct {
+	Step     string `json:"step"`
+	Progress int    `json:"progress"`
+}
+
+// bulkJobProgress is a single progress frame for a multi-file bulk job,
+// identifying which file it refers to alongside the run's overall position.
+type bulkJobProgress struct {
+	File     string `json:"file"`
+	Index    int    `json:"index"`
+	Total    int    `json:"total"`
+	Step     string `json:"step"`
+	Progress int    `json:"progress"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Job tracks a single asynchronous poster-fetch, thumbnail-generation, or
+// bulk-generation run. Progress holds either a jobProgress or a
+// bulkJobProgress frame, depending on Kind.
+type Job struct {
+	ID        string      `json:"id"`
+	Kind      string      `json:"kind"`
+	VideoPath string      `json:"videoPath,omitempty"`
+	Status    JobStatus   `json:"status"`
+	Progress  interface{} `json:"progress"`
+	Error     string      `json:"error,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+
+	mu        sync.Mutex
+	listeners []chan interface{}
+	cancel    context.CancelFunc
+}
+
+var (
+	jobsMu  sync.RWMutex
+	jobsReg = make(map[string]*Job)
+)
+
+// registerJob creates and registers a non-cancelable job, used by the
+// single-file thumbnail and poster jobs that don't take a context today.
+func registerJob(kind, videoPath string) *Job {
+	return registerCancelableJob(kind, videoPath, nil)
+}
+
+// registerCancelableJob creates and registers a job whose run can be
+// aborted via CancelJobHandler, which calls cancel.
+func registerCancelableJob(kind, videoPath string, cancel context.CancelFunc) *Job {
+	job := &Job{
+		ID:        uuid.NewString(),
+		Kind:      kind,
+		VideoPath: videoPath,
+		Status:    JobRunning,
+		CreatedAt: time.Now(),
+		cancel:    cancel,
+	}
+	jobsMu.Lock()
+	jobsReg[job.ID] = job
+	jobsMu.Unlock()
+	return job
+}
+
+// publish records a single-file progress frame and notifies any subscribers.
+func (j *Job) publish(step string, progress int) {
+	j.publishFrame(jobProgress{Step: step, Progress: progress})
+}
+
+// publishFrame records an arbitrary progress frame (jobProgress or
+// bulkJobProgress) and notifies any subscribers.
+func (j *Job) publishFrame(frame interface{}) {
+	j.mu.Lock()
+	j.Progress = frame
+	listeners := append([]chan interface{}(nil), j.listeners...)
+	j.mu.Unlock()
+
+	for _, ch := range listeners {
+		select {
+		case ch <- frame:
+		default:
+		}
+	}
+}
+
+func (j *Job) subscribe() chan interface{} {
+	ch := make(chan interface{}, 16)
+	j.mu.Lock()
+	j.listeners = append(j.listeners, ch)
+	j.mu.Unlock()
+	return ch
+}
+
+func (j *Job) unsubscribe(ch chan interface{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for i, l := range j.listeners {
+		if l == ch {
+			j.listeners = append(j.listeners[:i], j.listeners[i+1:]...)
+			break
+		}
+	}
+}
+
+func (j *Job) finish(err error) {
+	j.mu.Lock()
+	if err != nil {
+		j.Status = JobFailed
+		j.Error = err.Error()
+	} else {
+		j.Status = JobCompleted
+	}
+	listeners := append([]chan interface{}(nil), j.listeners...)
+	j.mu.Unlock()
+
+	for _, ch := range listeners {
+		close(ch)
+	}
+}
+
+// StartThumbnailJobHandler kicks off a thumbnail-generation job and returns
+// its job ID immediately; progress is retrieved via /jobs/{id}/events.
+func StartThumbnailJobHandler(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			VideoPath string `json:"videoPath"`
+			TimeMs    int    `json:"timeMs"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.VideoPath == "" {
+			http.Error(w, "videoPath is required", http.StatusBadRequest)
+			return
+		}
+
+		job := registerJob("thumbnail", req.VideoPath)
+		svc := services.NewService(cfg)
+
+		go func() {
+			err := svc.GenerateThumbnailWithProgress(req.VideoPath, req.TimeMs, job.publish)
+			job.finish(err)
+		}()
+
+		writeJobCreated(w, job)
+	}
+}
+
+// StartPosterJobHandler kicks off a TMDb poster-fetch job and returns its
+// job ID immediately; progress is retrieved via /jobs/{id}/events.
+func StartPosterJobHandler(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			VideoPath  string `json:"videoPath"`
+			MovieTitle string `json:"movieTitle"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.VideoPath == "" || req.MovieTitle == "" {
+			http.Error(w, "videoPath and movieTitle are required", http.StatusBadRequest)
+			return
+		}
+
+		job := registerJob("poster", req.VideoPath)
+		svc := services.NewService(cfg)
+
+		go func() {
+			err := svc.FetchMoviePoster(req.VideoPath, req.MovieTitle, job.publish)
+			job.finish(err)
+		}()
+
+		writeJobCreated(w, job)
+	}
+}
+
+// StartBulkThumbnailJobHandler kicks off a BulkGenerateThumbnailsWithOptions
+// run as a cancelable, trackable job, reporting per-file progress through
+// the same SSE mechanism as the single-file job.
+func StartBulkThumbnailJobHandler(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Workers int  `json:"workers"`
+			TimeMs  int  `json:"timeMs"`
+			Force   bool `json:"force"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		job := registerCancelableJob("bulk-thumbnail", "", cancel)
+		svc := services.NewService(cfg)
+
+		go func() {
+			_, _, err := svc.BulkGenerateThumbnailsWithOptions(ctx, services.BulkOptions{
+				Workers: req.Workers,
+				TimeMs:  req.TimeMs,
+				Force:   req.Force,
+			}, func(p services.BulkProgress) {
+				percent := 0
+				if p.Total > 0 {
+					percent = (p.Completed + p.Failed) * 100 / p.Total
+				}
+				job.publishFrame(bulkJobProgress{
+					File:     p.CurrentFile,
+					Index:    p.Completed + p.Failed,
+					Total:    p.Total,
+					Step:     string(p.Stage),
+					Progress: percent,
+				})
+			})
+			job.finish(err)
+		}()
+
+		writeJobCreated(w, job)
+	}
+}
+
+// CancelJobHandler aborts a running, cancelable job (currently only
+// bulk-thumbnail jobs support this) so its worker pool stops cleanly. It's
+// registered on a "/jobs/{id}/cancel" pattern; id is read via r.PathValue.
+func CancelJobHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	jobsMu.RLock()
+	job, ok := jobsReg[id]
+	jobsMu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if job.cancel == nil {
+		http.Error(w, "job does not support cancellation", http.StatusBadRequest)
+		return
+	}
+
+	job.cancel()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func writeJobCreated(w http.ResponseWriter, job *Job) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"id": job.ID})
+}
+
+// JobEventsHandler streams a job's progress via Server-Sent Events until
+// the job completes, fails, or the client disconnects. It's registered on a
+// "/jobs/{id}/events" pattern; id is read via r.PathValue.
+func JobEventsHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	jobsMu.RLock()
+	job, ok := jobsReg[id]
+	jobsMu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	sendFrame := func(progress interface{}) {
+		data, _ := json.Marshal(progress)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+	}
+
+	// Replay the last known progress immediately so a client that connects
+	// after the job already made progress isn't left blank.
+	if job.Progress != nil {
+		sendFrame(job.Progress)
+	}
+	if job.Status != JobRunning {
+		return
+	}
+
+	ch := job.subscribe()
+	defer job.unsubscribe(ch)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case progress, ok := <-ch:
+			if !ok {
+				return
+			}
+			sendFrame(progress)
+		}
+	}
+}
+
+// ListJobsHandler returns the current state of every known job, newest first.
+func ListJobsHandler(w http.ResponseWriter, _ *http.Request) {
+	jobsMu.RLock()
+	jobs := make([]*Job, 0, len(jobsReg))
+	for _, job := range jobsReg {
+		jobs = append(jobs, job)
+	}
+	jobsMu.RUnlock()
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].CreatedAt.After(jobs[k].CreatedAt) })
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(jobs); err != nil {
+		log.Printf("Error encoding job list: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"video-gallery/pkg/config"
+	"video-gallery/pkg/services"
+)
+
+// sessionCookieName is the cookie RequireAdmin accepts as an alternative to
+// resending HTTP Basic Auth credentials on every request.
+const sessionCookieName = "admin_session"
+
+// previewTokenParam is the query parameter RequirePreview checks for a
+// signed services.GeneratePreviewToken value.
+const previewTokenParam = "preview_token"
+
+// contentTokenParam is the query parameter RequireContent checks for a
+// signed services.GenerateContentToken value.
+const contentTokenParam = "content_token"
+
+// LoginHandler exchanges valid HTTP Basic Auth credentials for a signed,
+// short-lived session cookie, so the browser doesn't have to resend
+// credentials (or the page URL embed cfg.SecretKey) on every request.
+func LoginHandler(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !basicAuthMatches(cfg, r) {
+			time.Sleep(missingCredentialDelay)
+			w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     sessionCookieName,
+			Value:    services.GenerateSessionToken(cfg),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteStrictMode,
+			Expires:  time.Now().Add(24 * time.Hour),
+		})
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// RequireAdmin wraps next, authorizing the request if it carries either a
+// valid admin_session cookie (see LoginHandler) or valid HTTP Basic Auth
+// credentials. It's the browser-facing counterpart to RequireAdminAuth, and
+// should wrap every admin, bulk-generation, and thumbnail-job endpoint.
+func RequireAdmin(cfg *config.Config, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie(sessionCookieName); err == nil && services.VerifySessionToken(cfg, cookie.Value) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if basicAuthMatches(cfg, r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		time.Sleep(missingCredentialDelay)
+		w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// RequirePreview wraps next, authorizing the request if it carries a valid
+// preview_token query parameter (see services.GeneratePreviewToken) or
+// would itself pass RequireAdmin - letting gallery/feed links be shared
+// without exposing cfg.SecretKey or admin credentials.
+func RequirePreview(cfg *config.Config, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get(previewTokenParam); token != "" && services.VerifyPreviewToken(cfg, token) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if cookie, err := r.Cookie(sessionCookieName); err == nil && services.VerifySessionToken(cfg, cookie.Value) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if basicAuthMatches(cfg, r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// RequireContent wraps next, authorizing the request if it carries a valid
+// content_token query parameter (see services.GenerateContentToken) or would
+// itself pass RequirePreview - it's what guards the thumbnail URLs GetVideos
+// embeds in gallery/feed responses, so those URLs stay safe to share without
+// also handing out the admin secret or a full preview token.
+func RequireContent(cfg *config.Config, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get(contentTokenParam); token != "" && services.VerifyContentToken(cfg, token) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if token := r.URL.Query().Get(previewTokenParam); token != "" && services.VerifyPreviewToken(cfg, token) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if cookie, err := r.Cookie(sessionCookieName); err == nil && services.VerifySessionToken(cfg, cookie.Value) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if basicAuthMatches(cfg, r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	})
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"video-gallery/pkg/services"
+)
+
+// StatusHandler reports the background bucket watcher's current state:
+// when the index was last refreshed, how many objects it saw, and when
+// the next scan is scheduled.
+func StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(services.GetStatus())
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/storage"
+
+	"video-gallery/pkg/config"
+	"video-gallery/pkg/services"
+)
+
+// acceptsWebP reports whether an HTTP Accept header lists image/webp (the
+// way browsers that support it include it alongside image/jpeg) or the
+// */* wildcard.
+func acceptsWebP(accept string) bool {
+	return strings.Contains(accept, "image/webp") || strings.Contains(accept, "*/*")
+}
+
+// ThumbnailHandler serves videoPath's thumbnail, negotiating WebP vs the
+// legacy JPEG via the request's Accept header (falling back to JPEG if the
+// WebP variant hasn't been generated), and sets Cache-Control/ETag from the
+// source video's size and last-update time so browsers can cache aggressively
+// without polling GCS on every request.
+func ThumbnailHandler(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		videoPath := r.URL.Query().Get("video")
+		if videoPath == "" {
+			http.Error(w, "video is required", http.StatusBadRequest)
+			return
+		}
+
+		ctx := r.Context()
+		client, err := storage.NewClient(ctx)
+		if err != nil {
+			http.Error(w, "storage unavailable", http.StatusInternalServerError)
+			return
+		}
+		defer client.Close()
+
+		bucket := client.Bucket(cfg.BucketName)
+		videoAttrs, err := bucket.Object(videoPath).Attrs(ctx)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		format := "jpeg"
+		if acceptsWebP(r.Header.Get("Accept")) {
+			format = "webp"
+		}
+
+		objectName := services.ThumbnailVariantPath(videoPath, format)
+		obj := bucket.Object(objectName)
+		if _, err := obj.Attrs(ctx); err != nil {
+			// The WebP variant may not have been generated yet - fall back
+			// to the legacy JPEG rather than 404ing.
+			format = "jpeg"
+			objectName = services.ThumbnailVariantPath(videoPath, format)
+			obj = bucket.Object(objectName)
+		}
+
+		etag := fmt.Sprintf(`"%d-%d"`, videoAttrs.Size, videoAttrs.Updated.Unix())
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		reader, err := obj.NewReader(ctx)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer reader.Close()
+
+		w.Header().Set("Content-Type", contentTypeForFormat(format))
+		io.Copy(w, reader)
+	}
+}
+
+// contentTypeForFormat returns the Content-Type ThumbnailHandler should set
+// for a negotiated thumbnail format.
+func contentTypeForFormat(format string) string {
+	switch format {
+	case "webp":
+		return "image/webp"
+	case "avif":
+		return "image/avif"
+	default:
+		return "image/jpeg"
+	}
+}
@@ -22,6 +22,68 @@ type Video struct {
 	Gallery   string  `json:"-"`
 	Url       string  `json:"url"`
 	Thumbnail *string `json:"thumbnail,omitempty"`
+	Blurhash  string  `json:"blurhash,omitempty"`
+
+	// VideoPath is the video's raw object path (e.g. "cat/gallery/ep1.mp4"),
+	// used internally by GetVideos to build the negotiated Thumbnail URL; not
+	// serialized, since clients only need the resulting Thumbnail/Url links.
+	VideoPath string `json:"-"`
+
+	// BlurhashWidth and BlurhashHeight are the pixel dimensions the
+	// blurhash was computed from (see the thumbnail's sidecar JSON file),
+	// so a client can size its CSS placeholder to the right aspect ratio.
+	BlurhashWidth  int `json:"blurhashWidth,omitempty"`
+	BlurhashHeight int `json:"blurhashHeight,omitempty"`
+
+	// SeasonNumber and EpisodeNumber are set when the filename matches a
+	// recognized episodic pattern (e.g. "S01E02", "1x02"), marking this
+	// video as belonging to a Show rather than a plain movie Gallery.
+	SeasonNumber  *int `json:"seasonNumber,omitempty"`
+	EpisodeNumber *int `json:"episodeNumber,omitempty"`
+
+	// Info holds ffprobe-derived technical metadata, when available, so the
+	// frontend can render duration overlays and correct aspect-ratio
+	// containers without re-probing the video on every page load.
+	Info *VideoInfo `json:"info,omitempty"`
+}
+
+// VideoInfo holds technical metadata about a video file as reported by
+// ffprobe, persisted as GCS custom metadata on the video's thumbnail so it
+// travels with each gallery item.
+type VideoInfo struct {
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+	Duration   float64 `json:"duration"`
+	Fps        float64 `json:"fps"`
+	Bitrate    int64   `json:"bitrate"`
+	VideoCodec string  `json:"videoCodec"`
+	AudioCodec string  `json:"audioCodec"`
+	Format     string  `json:"format"`
+}
+
+// Episode represents a single episode of a Season
+type Episode struct {
+	Name   string  `json:"name"`
+	Number int     `json:"number"`
+	Url    string  `json:"url"`
+	Still  *string `json:"still,omitempty"`
+}
+
+// Season represents a numbered season of a Show, holding its episodes
+type Season struct {
+	Number   int       `json:"number"`
+	Poster   *string   `json:"poster,omitempty"`
+	Episodes []Episode `json:"episodes"`
+}
+
+// Show represents episodic content grouped by season, as an alternative to
+// a Gallery for folders that contain TV-style content instead of movies
+type Show struct {
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	Stub     string   `json:"-"`
+	Poster   *string  `json:"poster,omitempty"`
+	Seasons  []Season `json:"seasons"`
 }
 
 // Index represents the main index page data
@@ -0,0 +1,47 @@
+package router
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// gzipResponseWriter wraps http.ResponseWriter, transparently gzip-compressing
+// everything written to it.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz *gzip.Writer
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	return w.gz.Write(b)
+}
+
+// Flush satisfies http.Flusher by flushing the gzip stream before flushing
+// the underlying ResponseWriter, so handlers that stream (e.g. the SSE job
+// events endpoint) keep working through this middleware.
+func (w *gzipResponseWriter) Flush() {
+	w.gz.Flush()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Gzip compresses response bodies for clients that advertise support via
+// Accept-Encoding, leaving other responses untouched. It does not look at
+// Content-Type, so SSE streams are compressed as well, with each Flush
+// pushing the events written so far out to the client.
+func Gzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Add("Vary", "Accept-Encoding")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		next.ServeHTTP(&gzipResponseWriter{ResponseWriter: w, gz: gz}, r)
+	})
+}
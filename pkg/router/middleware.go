@@ -0,0 +1,53 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// statusRecorder wraps http.ResponseWriter to capture the status code
+// written, so Logging can report it after the handler returns.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Flush satisfies http.Flusher when the underlying ResponseWriter does, so
+// streaming handlers (e.g. the SSE job events endpoint) keep working behind
+// Logging.
+func (rec *statusRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Logging logs each request as a single structured line (method, path,
+// status, duration).
+func Logging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log.Printf("method=%s path=%s status=%d duration=%s", r.Method, r.URL.Path, rec.status, time.Since(start))
+	})
+}
+
+// Recover wraps next, converting a panic into a 500 response instead of
+// crashing the server, and logging the recovered value.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Printf("panic handling %s %s: %v", r.Method, r.URL.Path, rec)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
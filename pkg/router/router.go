@@ -0,0 +1,56 @@
+// Package router wraps the standard library's http.ServeMux (Go 1.22+
+// method+path patterns) with a shared middleware chain, replacing the
+// http.DefaultServeMux registrations and manual r.Method checks the server
+// previously used for every route.
+package router
+
+import "net/http"
+
+// Middleware wraps an http.Handler to add cross-cutting behavior (logging,
+// panic recovery, compression, auth) without each handler repeating it.
+type Middleware func(http.Handler) http.Handler
+
+// Router is a thin wrapper around http.ServeMux that applies a shared chain
+// of middleware to every route registered through it.
+type Router struct {
+	mux        *http.ServeMux
+	middleware []Middleware
+}
+
+// New creates a Router whose every route is wrapped with the given
+// middleware, applied outermost-first (the first middleware sees the
+// request before the rest).
+func New(middleware ...Middleware) *Router {
+	return &Router{mux: http.NewServeMux(), middleware: middleware}
+}
+
+// Handle registers handler for pattern (a standard net/http pattern, e.g.
+// "POST /admin/upload" or "/jobs/{id}/events"), wrapped with the router's
+// middleware chain plus any route-specific middleware passed here.
+func (rt *Router) Handle(pattern string, handler http.Handler, middleware ...Middleware) {
+	chain := make([]Middleware, 0, len(rt.middleware)+len(middleware))
+	chain = append(chain, rt.middleware...)
+	chain = append(chain, middleware...)
+	rt.mux.Handle(pattern, Chain(handler, chain...))
+}
+
+// HandleFunc is Handle for a plain handler function.
+func (rt *Router) HandleFunc(pattern string, handler http.HandlerFunc, middleware ...Middleware) {
+	rt.Handle(pattern, handler, middleware...)
+}
+
+// Chain wraps h with each middleware, applied outermost-first (mws[0] runs
+// first on the way in).
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// ServeHTTP implements http.Handler, delegating to the underlying mux, so a
+// Router can itself be mounted as a sub-handler (e.g. behind
+// http.StripPrefix).
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt.mux.ServeHTTP(w, r)
+}
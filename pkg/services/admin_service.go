@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// MaxUploadSize is the default largest video file accepted by UploadVideo
+// (5 GiB), used when appConfig.MaxUploadBytes isn't set.
+const MaxUploadSize = 5 << 30
+
+// defaultUploadThumbnailTimeMs is the frame offset used for the thumbnail
+// UploadVideo kicks off automatically after a successful upload, matching
+// the default of the bulk-generate-thumbnails command.
+const defaultUploadThumbnailTimeMs = 1000
+
+// ErrVideoTooLarge is returned when an uploaded video exceeds the configured
+// maximum upload size.
+var ErrVideoTooLarge = fmt.Errorf("video exceeds maximum upload size")
+
+// ErrInvalidPathSegment is returned when a category/gallery/filename
+// component could escape the media root (e.g. "..", "/", or an empty
+// segment).
+var ErrInvalidPathSegment = fmt.Errorf("invalid path segment")
+
+// validatePathSegment rejects path components that could be used to escape
+// the bucket prefix they're joined into, such as "..", an embedded "/", or
+// an empty string.
+func validatePathSegment(segment string) error {
+	if segment == "" || segment == "." || segment == ".." {
+		return ErrInvalidPathSegment
+	}
+	if strings.ContainsAny(segment, "/\\") {
+		return ErrInvalidPathSegment
+	}
+	return nil
+}
+
+// maxUploadBytes returns the configured upload size cap, falling back to
+// MaxUploadSize when appConfig.MaxUploadBytes is unset.
+func maxUploadBytes() int64 {
+	if appConfig.MaxUploadBytes > 0 {
+		return appConfig.MaxUploadBytes
+	}
+	return MaxUploadSize
+}
+
+// belongsToVideo reports whether objectName is the video at base (e.g.
+// "cat/gal/ep1") or one of its derived objects (thumbnail, variant, or
+// sidecar - all of which are uploaded as base plus a "." suffix, such as
+// "cat/gal/ep1.mp4" or "cat/gal/ep1.thumb-320.webp"). GCS prefix queries
+// match raw strings, so without this check a Prefix: base listing for
+// "cat/gal/ep1" would also match unrelated videos like "cat/gal/ep10.mp4".
+func belongsToVideo(objectName, base string) bool {
+	return objectName == base || strings.HasPrefix(objectName, base+".")
+}
+
+// UploadVideo validates and stages src as category/gallery/filename.
+// It first streams src to a local temp file (capped at the configured max
+// upload size), validates the result is a readable video container via
+// ffprobe, then uploads the temp file into the bucket and kicks off
+// thumbnail generation in the background.
+func UploadVideo(category, gallery, filename string, src io.Reader) error {
+	if err := validatePathSegment(category); err != nil {
+		return fmt.Errorf("invalid category: %v", err)
+	}
+	if err := validatePathSegment(gallery); err != nil {
+		return fmt.Errorf("invalid gallery: %v", err)
+	}
+	if err := validatePathSegment(filename); err != nil {
+		return fmt.Errorf("invalid filename: %v", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "video-upload-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %v", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	limit := maxUploadBytes()
+	written, err := io.Copy(tmpFile, &io.LimitedReader{R: src, N: limit + 1})
+	if err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to stage upload: %v", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to finalize staged upload: %v", err)
+	}
+	if written > limit {
+		return ErrVideoTooLarge
+	}
+
+	if _, err := getVideoDuration(tmpPath); err != nil {
+		return fmt.Errorf("not a valid video file: %v", err)
+	}
+
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create storage client: %v", err)
+	}
+	defer client.Close()
+
+	staged, err := os.Open(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to reopen staged upload: %v", err)
+	}
+	defer staged.Close()
+
+	objectName := strings.Join([]string{category, gallery, filename}, "/")
+	bucket := client.Bucket(appConfig.BucketName)
+	writer := bucket.Object(objectName).NewWriter(ctx)
+	if _, err := io.Copy(writer, staged); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to upload video: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finalize upload: %v", err)
+	}
+
+	log.Printf("Uploaded video: %s", objectName)
+	videoCache.Flush()
+
+	go func() {
+		if err := NewService(appConfig).GenerateThumbnail(objectName, defaultUploadThumbnailTimeMs); err != nil {
+			log.Printf("Warning: failed to generate thumbnail for %s: %v", objectName, err)
+		}
+	}()
+
+	return nil
+}
+
+// RenameVideo moves every object sharing the video's base name (the video file
+// plus any thumbnail) from its current object key to a new one.
+func RenameVideo(category, gallery, oldName, newName string) error {
+	if err := validatePathSegment(category); err != nil {
+		return fmt.Errorf("invalid category: %v", err)
+	}
+	if err := validatePathSegment(gallery); err != nil {
+		return fmt.Errorf("invalid gallery: %v", err)
+	}
+	if err := validatePathSegment(oldName); err != nil {
+		return fmt.Errorf("invalid oldName: %v", err)
+	}
+	if err := validatePathSegment(newName); err != nil {
+		return fmt.Errorf("invalid newName: %v", err)
+	}
+
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create storage client: %v", err)
+	}
+	defer client.Close()
+
+	bucket := client.Bucket(appConfig.BucketName)
+	oldBase := strings.Join([]string{category, gallery, oldName}, "/")
+	newBase := strings.Join([]string{category, gallery, newName}, "/")
+
+	it := bucket.Objects(ctx, &storage.Query{Prefix: oldBase})
+	renamed := 0
+	for {
+		obj, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error listing objects for %s: %v", oldBase, err)
+		}
+		if !belongsToVideo(obj.Name, oldBase) {
+			continue
+		}
+
+		newObjectName := newBase + strings.TrimPrefix(obj.Name, oldBase)
+		src := bucket.Object(obj.Name)
+		dst := bucket.Object(newObjectName)
+
+		if _, err := dst.CopierFrom(src).Run(ctx); err != nil {
+			return fmt.Errorf("failed to copy %s to %s: %v", obj.Name, newObjectName, err)
+		}
+		if err := src.Delete(ctx); err != nil {
+			return fmt.Errorf("failed to delete old object %s: %v", obj.Name, err)
+		}
+		renamed++
+	}
+
+	if renamed == 0 {
+		return fmt.Errorf("no objects found for video: %s", oldBase)
+	}
+
+	log.Printf("Renamed video: %s -> %s (%d objects)", oldBase, newBase, renamed)
+	videoCache.Flush()
+	return nil
+}
+
+// DeleteVideo removes every object sharing the video's base name (the video
+// file plus any thumbnail) from the bucket.
+func DeleteVideo(category, gallery, name string) error {
+	if err := validatePathSegment(category); err != nil {
+		return fmt.Errorf("invalid category: %v", err)
+	}
+	if err := validatePathSegment(gallery); err != nil {
+		return fmt.Errorf("invalid gallery: %v", err)
+	}
+	if err := validatePathSegment(name); err != nil {
+		return fmt.Errorf("invalid name: %v", err)
+	}
+
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create storage client: %v", err)
+	}
+	defer client.Close()
+
+	bucket := client.Bucket(appConfig.BucketName)
+	base := strings.Join([]string{category, gallery, name}, "/")
+
+	it := bucket.Objects(ctx, &storage.Query{Prefix: base})
+	deleted := 0
+	for {
+		obj, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error listing objects for %s: %v", base, err)
+		}
+		if !belongsToVideo(obj.Name, base) {
+			continue
+		}
+
+		if err := bucket.Object(obj.Name).Delete(ctx); err != nil {
+			return fmt.Errorf("failed to delete %s: %v", obj.Name, err)
+		}
+		deleted++
+	}
+
+	if deleted == 0 {
+		return fmt.Errorf("no objects found for video: %s", base)
+	}
+
+	log.Printf("Deleted video: %s (%d objects)", base, deleted)
+	videoCache.Flush()
+	return nil
+}
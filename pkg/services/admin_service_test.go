@@ -0,0 +1,53 @@
+package services
+
+import "testing"
+
+func TestValidatePathSegment(t *testing.T) {
+	tests := []struct {
+		name    string
+		segment string
+		wantErr bool
+	}{
+		{"plain name", "My Gallery", false},
+		{"empty", "", true},
+		{"dot", ".", true},
+		{"dot-dot", "..", true},
+		{"embedded forward slash", "cat/gal", true},
+		{"embedded backslash", `cat\gal`, true},
+		{"dot-dot embedded in a longer segment", "..cat", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePathSegment(tt.segment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePathSegment(%q) error = %v, wantErr %v", tt.segment, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBelongsToVideo(t *testing.T) {
+	tests := []struct {
+		name       string
+		objectName string
+		base       string
+		want       bool
+	}{
+		{"exact match", "cat/gal/ep1", "cat/gal/ep1", true},
+		{"video file", "cat/gal/ep1.mp4", "cat/gal/ep1", true},
+		{"thumbnail variant", "cat/gal/ep1.thumb-320.webp", "cat/gal/ep1", true},
+		{"blurhash sidecar", "cat/gal/ep1.blurhash.json", "cat/gal/ep1", true},
+		{"unrelated video with base as a literal prefix", "cat/gal/ep10.mp4", "cat/gal/ep1", false},
+		{"unrelated video with a suffix appended directly", "cat/gal/ep1-backup.mp4", "cat/gal/ep1", false},
+		{"different gallery entirely", "cat/other/ep1.mp4", "cat/gal/ep1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := belongsToVideo(tt.objectName, tt.base); got != tt.want {
+				t.Errorf("belongsToVideo(%q, %q) = %v, want %v", tt.objectName, tt.base, got, tt.want)
+			}
+		})
+	}
+}
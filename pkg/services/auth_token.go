@@ -0,0 +1,99 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"video-gallery/pkg/config"
+)
+
+// sessionTokenTTL, previewTokenTTL and contentTokenTTL bound how long a
+// token minted by GenerateSessionToken/GeneratePreviewToken/
+// GenerateContentToken remains valid.
+const (
+	sessionTokenTTL = 24 * time.Hour
+	previewTokenTTL = 7 * 24 * time.Hour
+	contentTokenTTL = 7 * 24 * time.Hour
+)
+
+// signToken produces a "expiry.signature" token, HMAC-signed over
+// purpose+expiry with cfg.SecretKey, so a token minted for one purpose
+// (session vs preview) can't be replayed as the other.
+func signToken(cfg *config.Config, purpose string, expiry time.Time) string {
+	exp := strconv.FormatInt(expiry.Unix(), 10)
+	return exp + "." + tokenSignature(cfg, purpose, exp)
+}
+
+// verifyToken reports whether token is an unexpired, correctly-signed
+// token for purpose.
+func verifyToken(cfg *config.Config, purpose, token string) bool {
+	exp, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if time.Now().Unix() > expUnix {
+		return false
+	}
+
+	expectedSig := tokenSignature(cfg, purpose, exp)
+	return subtle.ConstantTimeCompare([]byte(sig), []byte(expectedSig)) == 1
+}
+
+// tokenSignature computes the HMAC-SHA256 signature over purpose and exp,
+// keyed by cfg.SecretKey.
+func tokenSignature(cfg *config.Config, purpose, exp string) string {
+	mac := hmac.New(sha256.New, []byte(cfg.SecretKey))
+	mac.Write([]byte(purpose + "." + exp))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSessionToken mints a short-lived signed token proving the holder
+// already completed admin Basic Auth, so the browser can carry it in a
+// cookie instead of resending credentials on every request.
+func GenerateSessionToken(cfg *config.Config) string {
+	return signToken(cfg, "session", time.Now().Add(sessionTokenTTL))
+}
+
+// VerifySessionToken reports whether token is a currently-valid session
+// token minted by GenerateSessionToken.
+func VerifySessionToken(cfg *config.Config, token string) bool {
+	return verifyToken(cfg, "session", token)
+}
+
+// GeneratePreviewToken mints a signed, time-limited token that authorizes
+// read-only access to the public gallery/feed endpoints without exposing
+// cfg.SecretKey itself, so a gallery link can be shared and later expire.
+func GeneratePreviewToken(cfg *config.Config) string {
+	return signToken(cfg, "preview", time.Now().Add(previewTokenTTL))
+}
+
+// VerifyPreviewToken reports whether token is a currently-valid preview
+// token minted by GeneratePreviewToken.
+func VerifyPreviewToken(cfg *config.Config, token string) bool {
+	return verifyToken(cfg, "preview", token)
+}
+
+// GenerateContentToken mints a signed, time-limited token that authorizes
+// fetching an individual video's thumbnail without exposing cfg.SecretKey
+// itself, so links embedded in gallery/feed responses stay safe to share
+// regardless of whether the page was reached via the admin secret-key route
+// or a preview link.
+func GenerateContentToken(cfg *config.Config) string {
+	return signToken(cfg, "content", time.Now().Add(contentTokenTTL))
+}
+
+// VerifyContentToken reports whether token is a currently-valid content
+// token minted by GenerateContentToken.
+func VerifyContentToken(cfg *config.Config, token string) bool {
+	return verifyToken(cfg, "content", token)
+}
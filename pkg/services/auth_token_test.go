@@ -0,0 +1,97 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"video-gallery/pkg/config"
+)
+
+func TestGenerateAndVerifyTokenRoundTrip(t *testing.T) {
+	cfg := &config.Config{SecretKey: "test-secret"}
+
+	tests := []struct {
+		name   string
+		mint   func() string
+		verify func(string) bool
+	}{
+		{"session", func() string { return GenerateSessionToken(cfg) }, func(tok string) bool { return VerifySessionToken(cfg, tok) }},
+		{"preview", func() string { return GeneratePreviewToken(cfg) }, func(tok string) bool { return VerifyPreviewToken(cfg, tok) }},
+		{"content", func() string { return GenerateContentToken(cfg) }, func(tok string) bool { return VerifyContentToken(cfg, tok) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := tt.mint()
+			if !tt.verify(token) {
+				t.Errorf("%s token failed to verify against its own minting function", tt.name)
+			}
+		})
+	}
+}
+
+func TestVerifyTokenRejectsWrongPurpose(t *testing.T) {
+	cfg := &config.Config{SecretKey: "test-secret"}
+
+	sessionToken := GenerateSessionToken(cfg)
+	previewToken := GeneratePreviewToken(cfg)
+	contentToken := GenerateContentToken(cfg)
+
+	if VerifyPreviewToken(cfg, sessionToken) {
+		t.Error("a session token must not verify as a preview token")
+	}
+	if VerifyContentToken(cfg, sessionToken) {
+		t.Error("a session token must not verify as a content token")
+	}
+	if VerifySessionToken(cfg, previewToken) {
+		t.Error("a preview token must not verify as a session token")
+	}
+	if VerifyContentToken(cfg, previewToken) {
+		t.Error("a preview token must not verify as a content token")
+	}
+	if VerifySessionToken(cfg, contentToken) {
+		t.Error("a content token must not verify as a session token")
+	}
+	if VerifyPreviewToken(cfg, contentToken) {
+		t.Error("a content token must not verify as a preview token")
+	}
+}
+
+func TestVerifyTokenRejectsWrongSecret(t *testing.T) {
+	minted := GenerateSessionToken(&config.Config{SecretKey: "secret-a"})
+	if VerifySessionToken(&config.Config{SecretKey: "secret-b"}, minted) {
+		t.Error("a token signed with one secret must not verify under another")
+	}
+}
+
+func TestVerifyTokenRejectsExpiredToken(t *testing.T) {
+	cfg := &config.Config{SecretKey: "test-secret"}
+	expired := signToken(cfg, "session", time.Now().Add(-time.Hour))
+	if verifyToken(cfg, "session", expired) {
+		t.Error("an expired token must not verify")
+	}
+}
+
+func TestVerifyTokenRejectsTamperedSignature(t *testing.T) {
+	cfg := &config.Config{SecretKey: "test-secret"}
+	token := GenerateSessionToken(cfg)
+
+	exp, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		t.Fatalf("minted token %q missing expected exp.sig separator", token)
+	}
+	tampered := exp + "." + sig + "x"
+	if VerifySessionToken(cfg, tampered) {
+		t.Error("a tampered signature must not verify")
+	}
+}
+
+func TestVerifyTokenRejectsMalformedToken(t *testing.T) {
+	cfg := &config.Config{SecretKey: "test-secret"}
+	for _, malformed := range []string{"", "no-separator", "."} {
+		if verifyToken(cfg, "session", malformed) {
+			t.Errorf("malformed token %q must not verify", malformed)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strconv"
+
+	"github.com/buckket/go-blurhash"
+)
+
+// blurhashComponentsX and blurhashComponentsY control the resolution of the
+// generated hash; 4x3 is a common default that keeps the string short while
+// still conveying rough shape and color.
+const (
+	blurhashComponentsX = 4
+	blurhashComponentsY = 3
+)
+
+// blurhashMetadataKey, blurhashWidthMetadataKey and blurhashHeightMetadataKey
+// are the GCS custom metadata keys a thumbnail's blurhash and source
+// dimensions are persisted under, so GetVideos can read them straight off
+// the bucket listing instead of fetching each thumbnail's sidecar file.
+const (
+	blurhashMetadataKey       = "blurhash"
+	blurhashWidthMetadataKey  = "blurhashWidth"
+	blurhashHeightMetadataKey = "blurhashHeight"
+)
+
+// EncodeBlurhashMetadata serializes a blurhash and the pixel dimensions it
+// was computed from into the GCS custom metadata keys used to persist them
+// alongside a thumbnail object.
+func EncodeBlurhashMetadata(hash string, width, height int) map[string]string {
+	return map[string]string{
+		blurhashMetadataKey:       hash,
+		blurhashWidthMetadataKey:  strconv.Itoa(width),
+		blurhashHeightMetadataKey: strconv.Itoa(height),
+	}
+}
+
+// DecodeBlurhashMetadata reads a thumbnail's blurhash and dimensions back
+// out of its GCS custom metadata, returning a zero-value width/height if
+// they weren't recorded (e.g. metadata predates their introduction).
+func DecodeBlurhashMetadata(metadata map[string]string) (hash string, width int, height int) {
+	hash = metadata[blurhashMetadataKey]
+	width, _ = strconv.Atoi(metadata[blurhashWidthMetadataKey])
+	height, _ = strconv.Atoi(metadata[blurhashHeightMetadataKey])
+	return hash, width, height
+}
+
+// ComputeBlurhash decodes the image at imagePath and returns its BlurHash
+// string, for use as an LQIP placeholder while the real thumbnail loads.
+func ComputeBlurhash(imagePath string) (string, error) {
+	hash, _, _, err := ComputeBlurhashWithDims(imagePath)
+	return hash, err
+}
+
+// ComputeBlurhashWithDims decodes the image at imagePath and returns its
+// BlurHash string along with the pixel dimensions it was computed from, for
+// callers that persist both (e.g. a blurhash sidecar file).
+func ComputeBlurhashWithDims(imagePath string) (string, int, int, error) {
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to open image: %v", err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to decode image: %v", err)
+	}
+
+	hash, err := blurhash.Encode(blurhashComponentsX, blurhashComponentsY, img)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to compute blurhash: %v", err)
+	}
+
+	bounds := img.Bounds()
+	return hash, bounds.Dx(), bounds.Dy(), nil
+}
+
+// previewWidth and previewHeight are the dimensions used when decoding a
+// blurhash back into a preview image for variance-based validation.
+const (
+	previewWidth  = 4
+	previewHeight = 3
+)
+
+// DecodeBlurhashPreview decodes hash into a small preview image, for callers
+// that need to inspect the hash's content (e.g. to reject near-solid frames).
+func DecodeBlurhashPreview(hash string) (image.Image, error) {
+	img, err := blurhash.Decode(hash, previewWidth, previewHeight, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode blurhash: %v", err)
+	}
+	return img, nil
+}
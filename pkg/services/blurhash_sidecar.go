@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// blurhashSidecarSuffix is appended to a thumbnail's object name to locate
+// its blurhash sidecar JSON file, e.g. "cat/gallery/video.jpg.blurhash.json".
+const blurhashSidecarSuffix = ".blurhash.json"
+
+// BlurhashSidecar is the sidecar JSON document written next to a thumbnail,
+// carrying its LQIP blurhash string and the pixel dimensions it was
+// computed from.
+type BlurhashSidecar struct {
+	Blurhash string `json:"blurhash"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+// writeBlurhashSidecar computes imagePath's blurhash and uploads it as a
+// sidecar JSON file next to thumbnailObjectName, so clients can render a
+// CSS-decoded placeholder before the real thumbnail has loaded. It returns
+// the computed sidecar so callers can also persist it as object metadata
+// without recomputing the hash.
+func writeBlurhashSidecar(ctx context.Context, bucket *storage.BucketHandle, imagePath, thumbnailObjectName string) (BlurhashSidecar, error) {
+	hash, width, height, err := ComputeBlurhashWithDims(imagePath)
+	if err != nil {
+		return BlurhashSidecar{}, fmt.Errorf("computing blurhash: %v", err)
+	}
+	sidecar := BlurhashSidecar{Blurhash: hash, Width: width, Height: height}
+
+	data, err := json.Marshal(sidecar)
+	if err != nil {
+		return BlurhashSidecar{}, fmt.Errorf("encoding blurhash sidecar: %v", err)
+	}
+
+	w := bucket.Object(thumbnailObjectName + blurhashSidecarSuffix).NewWriter(ctx)
+	w.ContentType = "application/json"
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return BlurhashSidecar{}, fmt.Errorf("uploading blurhash sidecar: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return BlurhashSidecar{}, err
+	}
+	return sidecar, nil
+}
+
+// updateBlurhashMetadata merges a blurhash sidecar's values into
+// thumbnailObjectName's existing custom metadata, so GetVideos can read them
+// straight off the bucket listing instead of fetching the sidecar file.
+func updateBlurhashMetadata(ctx context.Context, bucket *storage.BucketHandle, thumbnailObjectName string, sidecar BlurhashSidecar) error {
+	obj := bucket.Object(thumbnailObjectName)
+	attrs, err := obj.Attrs(ctx)
+	if err != nil {
+		return fmt.Errorf("reading thumbnail attrs: %v", err)
+	}
+
+	metadata := attrs.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	for key, value := range EncodeBlurhashMetadata(sidecar.Blurhash, sidecar.Width, sidecar.Height) {
+		metadata[key] = value
+	}
+
+	_, err = obj.Update(ctx, storage.ObjectAttrsToUpdate{Metadata: metadata})
+	return err
+}
+
+// readBlurhashSidecar fetches and decodes the blurhash sidecar JSON for
+// thumbnailObjectName, returning ok=false if it doesn't exist (e.g. the
+// thumbnail predates this feature and hasn't been backfilled yet).
+func readBlurhashSidecar(ctx context.Context, bucket *storage.BucketHandle, thumbnailObjectName string) (BlurhashSidecar, bool) {
+	reader, err := bucket.Object(thumbnailObjectName + blurhashSidecarSuffix).NewReader(ctx)
+	if err != nil {
+		return BlurhashSidecar{}, false
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return BlurhashSidecar{}, false
+	}
+
+	var sidecar BlurhashSidecar
+	if err := json.Unmarshal(data, &sidecar); err != nil {
+		return BlurhashSidecar{}, false
+	}
+	return sidecar, true
+}
+
+// BackfillBlurhashSidecars scans the bucket for thumbnails that don't yet
+// have a blurhash sidecar and writes one for each, without regenerating the
+// thumbnail itself - a one-time migration for thumbnails created before
+// sidecar blurhashes existed.
+func (s *Service) BackfillBlurhashSidecars(ctx context.Context, progressCb BulkProgressCallback) (int, int, error) {
+	sendProgress := func(p BulkProgress) {
+		if progressCb != nil {
+			progressCb(p)
+		}
+	}
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create storage client: %v", err)
+	}
+	defer client.Close()
+
+	bucket := client.Bucket(s.config.BucketName)
+	imageExtensions := []string{".jpg", ".jpeg", ".png"}
+
+	sendProgress(BulkProgress{Stage: BulkStageListing})
+
+	var thumbnails []string
+	it := bucket.Objects(ctx, nil)
+	for {
+		obj, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("error iterating objects: %v", err)
+		}
+		if strings.HasSuffix(obj.Name, blurhashSidecarSuffix) {
+			continue
+		}
+
+		parts := strings.Split(obj.Name, "/")
+		if len(parts) != 3 || parts[2] == "" {
+			continue
+		}
+
+		filename := parts[2]
+		for _, ext := range imageExtensions {
+			if strings.HasSuffix(filename, ext) {
+				thumbnails = append(thumbnails, obj.Name)
+				break
+			}
+		}
+	}
+
+	total := len(thumbnails)
+	sendProgress(BulkProgress{Total: total, Stage: BulkStageListing})
+
+	outputDir := filepath.Join(os.TempDir(), "video-gallery-thumbnails")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return 0, 0, fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	var completed, failed int
+	for _, thumbnailPath := range thumbnails {
+		if ctx.Err() != nil {
+			return completed, failed, ctx.Err()
+		}
+
+		if _, ok := readBlurhashSidecar(ctx, bucket, thumbnailPath); ok {
+			completed++
+			sendProgress(BulkProgress{Total: total, Completed: completed, Failed: failed, CurrentFile: thumbnailPath, Stage: BulkStageDone})
+			continue
+		}
+
+		sendProgress(BulkProgress{Total: total, Completed: completed, Failed: failed, CurrentFile: thumbnailPath, Stage: BulkStageDownloading})
+		tmpPath := filepath.Join(outputDir, getSafeFilename(thumbnailPath))
+		if err := downloadFile(ctx, bucket, thumbnailPath, tmpPath); err != nil {
+			log.Printf("Error downloading thumbnail %s: %v", thumbnailPath, err)
+			failed++
+			continue
+		}
+
+		sendProgress(BulkProgress{Total: total, Completed: completed, Failed: failed, CurrentFile: thumbnailPath, Stage: BulkStageBackfilling})
+		sidecar, sidecarErr := writeBlurhashSidecar(ctx, bucket, tmpPath, thumbnailPath)
+		os.Remove(tmpPath)
+		if sidecarErr != nil {
+			log.Printf("Error backfilling blurhash sidecar for %s: %v", thumbnailPath, sidecarErr)
+			failed++
+		} else if err := updateBlurhashMetadata(ctx, bucket, thumbnailPath, sidecar); err != nil {
+			log.Printf("Error updating blurhash metadata for %s: %v", thumbnailPath, err)
+			failed++
+		} else {
+			completed++
+		}
+		sendProgress(BulkProgress{Total: total, Completed: completed, Failed: failed, CurrentFile: thumbnailPath, Stage: BulkStageDone})
+	}
+
+	return completed, failed, nil
+}
@@ -7,8 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/url"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"video-gallery/pkg/config"
@@ -26,6 +28,7 @@ var appConfig *config.Config
 // InitService initializes the service with the given configuration
 func InitService(cfg *config.Config) {
 	appConfig = cfg
+	go WatchBucket(context.Background())
 }
 
 // GetCategories returns all categories with their galleries
@@ -64,13 +67,18 @@ func GetGallery(stub string) (models.Gallery, error) {
 	return models.Gallery{}, fmt.Errorf("gallery not found")
 }
 
-// GetGalleries returns all galleries with their videos
+// GetGalleries returns all galleries with their videos. Videos detected as
+// episodes (see parseEpisode) are excluded here and surfaced instead through
+// GetShows, grouped into seasons.
 func GetGalleries() []models.Gallery {
 	videos := GetVideos()
-	secretKey := appConfig.GetSecretKey()
+	secretKey := appConfig.SecretKey
 
 	var galleries []models.Gallery
 	for _, video := range videos {
+		if video.SeasonNumber != nil {
+			continue
+		}
 		category := video.Category
 		gallery := video.Gallery
 		// Check if gallery already exists
@@ -99,6 +107,110 @@ func GetGalleries() []models.Gallery {
 	return galleries
 }
 
+// GetShow returns a show by its stub
+func GetShow(stub string) (models.Show, error) {
+	for _, show := range GetShows() {
+		if show.Stub == stub {
+			return show, nil
+		}
+	}
+	return models.Show{}, fmt.Errorf("show not found")
+}
+
+// GetShows returns all shows, grouping episode videos (see parseEpisode)
+// by gallery name into a Show with nested Season -> Episode structure.
+func GetShows() []models.Show {
+	videos := GetVideos()
+	secretKey := appConfig.SecretKey
+
+	var shows []models.Show
+	for _, video := range videos {
+		if video.SeasonNumber == nil || video.EpisodeNumber == nil {
+			continue
+		}
+
+		showIndex := -1
+		for i, show := range shows {
+			if show.Name == video.Gallery {
+				showIndex = i
+				break
+			}
+		}
+		if showIndex == -1 {
+			hash := sha1.New()
+			hash.Write([]byte(video.Gallery + secretKey))
+			stub := base64.URLEncoding.EncodeToString(hash.Sum(nil))[0:4]
+
+			shows = append(shows, models.Show{
+				Name:     video.Gallery,
+				Category: video.Category,
+				Stub:     "/show/" + stub,
+			})
+			showIndex = len(shows) - 1
+		}
+
+		seasonIndex := -1
+		for i, season := range shows[showIndex].Seasons {
+			if season.Number == *video.SeasonNumber {
+				seasonIndex = i
+				break
+			}
+		}
+		if seasonIndex == -1 {
+			shows[showIndex].Seasons = append(shows[showIndex].Seasons, models.Season{
+				Number: *video.SeasonNumber,
+			})
+			seasonIndex = len(shows[showIndex].Seasons) - 1
+		}
+
+		shows[showIndex].Seasons[seasonIndex].Episodes = append(shows[showIndex].Seasons[seasonIndex].Episodes, models.Episode{
+			Name:   video.Name,
+			Number: *video.EpisodeNumber,
+			Url:    video.Url,
+			Still:  video.Thumbnail,
+		})
+	}
+
+	for _, show := range shows {
+		sort.Slice(show.Seasons, func(i, j int) bool {
+			return show.Seasons[i].Number < show.Seasons[j].Number
+		})
+		for _, season := range show.Seasons {
+			sort.Slice(season.Episodes, func(i, j int) bool {
+				return season.Episodes[i].Number < season.Episodes[j].Number
+			})
+		}
+	}
+
+	return shows
+}
+
+// episodePatterns match the common release-name conventions for episodic
+// content: "S01E02", "1x02", and a "Season 1 ... Episode 2" style name.
+var episodePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)S(\d{1,2})E(\d{1,3})`),
+	regexp.MustCompile(`(?i)(\d{1,2})x(\d{1,3})`),
+	regexp.MustCompile(`(?i)Season\s*(\d{1,2}).*?Episode\s*(\d{1,3})`),
+}
+
+// parseEpisode extracts a season and episode number from a filename (or
+// path) following one of episodePatterns, reporting ok=false if none match.
+func parseEpisode(name string) (season int, episode int, ok bool) {
+	for _, pattern := range episodePatterns {
+		match := pattern.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		s, errS := strconv.Atoi(match[1])
+		e, errE := strconv.Atoi(match[2])
+		if errS != nil || errE != nil {
+			continue
+		}
+		return s, e, true
+	}
+	return 0, 0, false
+}
+
 // GetVideos returns all videos from the storage bucket
 func GetVideos() []models.Video {
 	// Check if Videos are cached
@@ -109,7 +221,7 @@ func GetVideos() []models.Video {
 	log.Println("Getting Videos")
 
 	// Get bucket name from config
-	bucketName := appConfig.GetBucketName()
+	bucketName := appConfig.BucketName
 
 	// Initialize Cloud Storage
 	storageClient, err := storage.NewClient(context.Background())
@@ -151,14 +263,20 @@ func GetVideos() []models.Video {
 			}
 			// Remove extension from the filename
 			fileBase := extensionRegex.ReplaceAll([]byte(filename), []byte(""))
+			season, episode, isEpisode := parseEpisode(string(fileBase))
 
 			// If Video doesn't exist
 			if _, ok := videosMap[string(fileBase)]; !ok {
-				videosMap[string(fileBase)] = models.Video{
+				video := models.Video{
 					Name:     string(fileBase),
 					Category: category,
 					Gallery:  gallery,
 				}
+				if isEpisode {
+					video.SeasonNumber = &season
+					video.EpisodeNumber = &episode
+				}
+				videosMap[string(fileBase)] = video
 			}
 
 			// Check if video already exists
@@ -166,22 +284,44 @@ func GetVideos() []models.Video {
 				for _, extension := range videoExtensions {
 					if strings.HasSuffix(filename, extension) {
 						videosMap[string(fileBase)] = models.Video{
-							Name:      video.Name,
-							Category:  video.Category,
-							Gallery:   video.Gallery,
-							Url:       signedUrl,
-							Thumbnail: video.Thumbnail,
+							Name:           video.Name,
+							Category:       video.Category,
+							Gallery:        video.Gallery,
+							Url:            signedUrl,
+							Thumbnail:      video.Thumbnail,
+							Blurhash:       video.Blurhash,
+							BlurhashWidth:  video.BlurhashWidth,
+							BlurhashHeight: video.BlurhashHeight,
+							VideoPath:      file.Name,
+							SeasonNumber:   video.SeasonNumber,
+							EpisodeNumber:  video.EpisodeNumber,
+							Info:           video.Info,
 						}
 					}
 				}
 				for _, extension := range imageExtensions {
 					if strings.HasSuffix(filename, extension) {
+						// Read the blurhash straight off this object's
+						// metadata (already fetched by the bucket listing)
+						// rather than fetching its sidecar file, which would
+						// cost one extra blocking GCS read per video on
+						// every cache rebuild. BackfillBlurhashSidecars
+						// keeps older thumbnails' metadata in sync for
+						// videos that predate this metadata.
+						blurhash, blurhashWidth, blurhashHeight := DecodeBlurhashMetadata(file.Metadata)
 						videosMap[string(fileBase)] = models.Video{
-							Name:      video.Name,
-							Category:  video.Category,
-							Gallery:   video.Gallery,
-							Url:       video.Url,
-							Thumbnail: &signedUrl,
+							Name:           video.Name,
+							Category:       video.Category,
+							Gallery:        video.Gallery,
+							Url:            video.Url,
+							Thumbnail:      &signedUrl,
+							Blurhash:       blurhash,
+							BlurhashWidth:  blurhashWidth,
+							BlurhashHeight: blurhashHeight,
+							VideoPath:      video.VideoPath,
+							SeasonNumber:   video.SeasonNumber,
+							EpisodeNumber:  video.EpisodeNumber,
+							Info:           DecodeVideoInfoMetadata(file.Metadata),
 						}
 					}
 				}
@@ -189,8 +329,19 @@ func GetVideos() []models.Video {
 		}
 	}
 	// Convert Map to Array
+	contentToken := GenerateContentToken(appConfig)
 	var videos []models.Video
 	for _, video := range videosMap {
+		// Point Thumbnail at the negotiated /content/thumbnail endpoint
+		// instead of the raw signed JPEG URL, so the gallery/feed actually
+		// exercise ThumbnailHandler's Accept-based WebP/AVIF negotiation and
+		// caching. It's signed with a content token rather than
+		// appConfig.SecretKey, so a shared preview link's thumbnails don't
+		// also disclose the admin secret.
+		if video.Thumbnail != nil && video.VideoPath != "" {
+			thumbnailURL := fmt.Sprintf("/content/thumbnail?video=%s&content_token=%s", url.QueryEscape(video.VideoPath), url.QueryEscape(contentToken))
+			video.Thumbnail = &thumbnailURL
+		}
 		videos = append(videos, video)
 	}
 
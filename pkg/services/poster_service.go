@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"cloud.google.com/go/storage"
@@ -47,10 +48,14 @@ func (s *Service) FetchMoviePoster(videoPath string, movieTitle string, progress
 	sendProgress("Searching for movie", 15)
 
 	// Clean the movie title for better search results
-	cleanTitle := cleanMovieTitle(movieTitle)
+	cleanTitle, year := parseMovieTitle(movieTitle)
 
-	// Search for movie
+	// Search for movie, passing the extracted year (if any) to sharply
+	// improve match accuracy for common titles
 	searchURL := fmt.Sprintf("%s?api_key=%s&query=%s", tmdbSearchURL, apiKey, url.QueryEscape(cleanTitle))
+	if year != "" {
+		searchURL += "&year=" + year
+	}
 	resp, err := http.Get(searchURL)
 	if err != nil {
 		return fmt.Errorf("failed to search movie: %v", err)
@@ -71,8 +76,8 @@ func (s *Service) FetchMoviePoster(videoPath string, movieTitle string, progress
 		return fmt.Errorf("no movie found for title: %s", movieTitle)
 	}
 
-	// Try to find exact match first, then fall back to partial match
-	movie := findBestMatch(result.Results, cleanTitle)
+	// Prefer an exact match from the right year, then any exact match, then a partial match
+	movie := findBestMatch(result.Results, cleanTitle, year)
 	if movie.PosterPath == nil || *movie.PosterPath == "" {
 		return fmt.Errorf("no poster available for: %s", movieTitle)
 	}
@@ -137,7 +142,7 @@ func (s *Service) FetchMoviePoster(videoPath string, movieTitle string, progress
 	// Clear old thumbnail
 	bucket.Object(thumbnailPath).Delete(ctx)
 
-	if err := uploadFile(ctx, bucket, cleanTmpPath, thumbnailPath); err != nil {
+	if err := uploadThumbnailWithBlurhash(ctx, bucket, cleanTmpPath, thumbnailPath); err != nil {
 		return fmt.Errorf("error uploading poster: %v", err)
 	}
 
@@ -157,9 +162,12 @@ func (s *Service) SearchMoviePoster(movieTitle string) ([]MoviePosterResult, err
 	}
 
 	// Clean the movie title for better search results
-	cleanTitle := cleanMovieTitle(movieTitle)
+	cleanTitle, year := parseMovieTitle(movieTitle)
 
 	searchURL := fmt.Sprintf("%s?api_key=%s&query=%s", tmdbSearchURL, apiKey, url.QueryEscape(cleanTitle))
+	if year != "" {
+		searchURL += "&year=" + year
+	}
 	resp, err := http.Get(searchURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search movie: %v", err)
@@ -206,14 +214,17 @@ func extractYear(releaseDate string) string {
 	return ""
 }
 
-// findBestMatch finds the best matching movie from results
-// First tries exact match (case-insensitive), then falls back to partial match
+// findBestMatch finds the best matching movie from results. Among exact
+// title matches (case-insensitive) it prefers - when a year was extracted
+// from the filename - the one whose release year agrees with it, else the
+// first; then it falls back to a partial (contains) match, and finally the
+// first result.
 func findBestMatch(results []struct {
 	ID          int     `json:"id"`
 	Title       string  `json:"title"`
 	PosterPath  *string `json:"poster_path"`
 	ReleaseDate string  `json:"release_date"`
-}, searchTitle string) struct {
+}, searchTitle string, year string) struct {
 	ID          int     `json:"id"`
 	Title       string  `json:"title"`
 	PosterPath  *string `json:"poster_path"`
@@ -221,12 +232,24 @@ func findBestMatch(results []struct {
 } {
 	searchLower := strings.ToLower(searchTitle)
 
-	// First pass: look for exact match
-	for _, movie := range results {
+	// First pass: look for exact title match, preferring one whose release
+	// year agrees with the year extracted from the filename
+	var exactMatches []int
+	for i, movie := range results {
 		if strings.ToLower(movie.Title) == searchLower {
-			return movie
+			exactMatches = append(exactMatches, i)
+		}
+	}
+	if year != "" {
+		for _, i := range exactMatches {
+			if extractYear(results[i].ReleaseDate) == year {
+				return results[i]
+			}
 		}
 	}
+	if len(exactMatches) > 0 {
+		return results[exactMatches[0]]
+	}
 
 	// Second pass: look for partial match (contains)
 	for _, movie := range results {
@@ -239,21 +262,74 @@ func findBestMatch(results []struct {
 	return results[0]
 }
 
-// cleanMovieTitle removes common metadata from movie titles for better search results
-// Examples: "Empire Strikes Back (Despecialized v2 0)" -> "Empire Strikes Back"
-func cleanMovieTitle(title string) string {
-	// Remove content in parentheses (e.g., version info, year, quality)
-	if idx := strings.Index(title, "("); idx != -1 {
-		title = title[:idx]
-	}
+// noise token patterns recognized by parseMovieTitle. Each matches a whole
+// delimiter-separated token (case-insensitive).
+var (
+	yearPattern       = regexp.MustCompile(`^(19|20)\d{2}$`)
+	resolutionPattern = regexp.MustCompile(`(?i)^(\d{3,4}p|4k)$`)
+	sourcePattern     = regexp.MustCompile(`(?i)^(bluray|web-?dl|hdtv|dvdrip|brrip|webrip)$`)
+	codecPattern      = regexp.MustCompile(`(?i)^(x26[45]|h\.?26[45]|hevc|avc|xvid|divx)(-\w+)?$`)
+	audioPattern      = regexp.MustCompile(`(?i)^(aac|ac3|dts|ddp?\d*)$`)
+	titleSplitPattern = regexp.MustCompile(`[._\s]+`)
+)
+
+// isNoiseToken reports whether token is a recognized release-metadata
+// marker (resolution, source, codec, or audio format) rather than title text.
+func isNoiseToken(token string) bool {
+	return resolutionPattern.MatchString(token) ||
+		sourcePattern.MatchString(token) ||
+		codecPattern.MatchString(token) ||
+		audioPattern.MatchString(token)
+}
 
-	// Remove content in brackets
-	if idx := strings.Index(title, "["); idx != -1 {
+// parseMovieTitle splits a release-style filename into a clean title and,
+// when present, its release year. It walks tokens left to right, splitting
+// on ".", "_", and spaces, and stops accumulating title words at the first
+// token that is unambiguously metadata: a resolution, source, codec, or
+// audio marker, or a 4-digit year that is itself followed by one of those
+// markers or by another year (so a title that legitimately contains a number, like
+// "2001 A Space Odyssey", is left untouched).
+//
+// Examples:
+//
+//	"Movie.Name.2019.1080p.BluRay.x264-GROUP.mkv" -> "Movie Name", "2019"
+//	"Empire Strikes Back (Despecialized v2 0)"    -> "Empire Strikes Back", ""
+//	"2001 A Space Odyssey"                        -> "2001 A Space Odyssey", ""
+func parseMovieTitle(title string) (cleanTitle string, year string) {
+	// Remove content in parentheses/brackets (version info, commentary, etc.)
+	if idx := strings.IndexAny(title, "(["); idx != -1 {
 		title = title[:idx]
 	}
-
-	// Trim whitespace
 	title = strings.TrimSpace(title)
 
-	return title
+	tokens := titleSplitPattern.Split(title, -1)
+
+	var titleTokens []string
+	for i, token := range tokens {
+		if token == "" {
+			continue
+		}
+
+		if yearPattern.MatchString(token) {
+			followedByMetadata := false
+			if i+1 < len(tokens) {
+				next := tokens[i+1]
+				followedByMetadata = next != "" && (isNoiseToken(next) || yearPattern.MatchString(next))
+			}
+			if followedByMetadata {
+				year = token
+				break
+			}
+			titleTokens = append(titleTokens, token)
+			continue
+		}
+
+		if isNoiseToken(token) {
+			break
+		}
+
+		titleTokens = append(titleTokens, token)
+	}
+
+	return strings.Join(titleTokens, " "), year
 }
@@ -0,0 +1,67 @@
+package services
+
+import "testing"
+
+func TestParseMovieTitle(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantTitle string
+		wantYear  string
+	}{
+		{
+			name:      "release name with full metadata",
+			input:     "Movie.Name.2019.1080p.BluRay.x264-GROUP.mkv",
+			wantTitle: "Movie Name",
+			wantYear:  "2019",
+		},
+		{
+			name:      "parenthetical version info",
+			input:     "Empire Strikes Back (Despecialized v2 0)",
+			wantTitle: "Empire Strikes Back",
+			wantYear:  "",
+		},
+		{
+			name:      "bracketed metadata",
+			input:     "The Matrix [1999] [1080p]",
+			wantTitle: "The Matrix",
+			wantYear:  "",
+		},
+		{
+			name:      "title containing a standalone 4-digit number",
+			input:     "2001 A Space Odyssey",
+			wantTitle: "2001 A Space Odyssey",
+			wantYear:  "",
+		},
+		{
+			name:      "WEBRip source with underscores",
+			input:     "Some_Movie_2021_720p_WEBRip_AAC",
+			wantTitle: "Some Movie",
+			wantYear:  "2021",
+		},
+		{
+			name:      "HDTV source, no year",
+			input:     "Documentary.HDTV.x264-GROUP",
+			wantTitle: "Documentary",
+			wantYear:  "",
+		},
+		{
+			name:      "plain title with no metadata",
+			input:     "Spirited Away",
+			wantTitle: "Spirited Away",
+			wantYear:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotTitle, gotYear := parseMovieTitle(tt.input)
+			if gotTitle != tt.wantTitle {
+				t.Errorf("parseMovieTitle(%q) title = %q, want %q", tt.input, gotTitle, tt.wantTitle)
+			}
+			if gotYear != tt.wantYear {
+				t.Errorf("parseMovieTitle(%q) year = %q, want %q", tt.input, gotYear, tt.wantYear)
+			}
+		})
+	}
+}
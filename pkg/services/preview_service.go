@@ -0,0 +1,496 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// PreviewOptions configures a hover-preview clip: how many short segments
+// are sampled across the video, how long each segment is, the output
+// container, and the box it's scaled to fit within.
+type PreviewOptions struct {
+	Format          string // "webp" (animated) or "mp4" (short muted clip)
+	SegmentCount    int    // number of segments distributed across the video
+	SegmentDuration float64
+	Width           int
+	Height          int
+}
+
+// DefaultPreviewOptions produce a ~6s animated WebP at 240p from four 1.5s
+// segments spread across the video, mirroring the hover-preview behavior of
+// tools like PhotoPrism/Photoview.
+var DefaultPreviewOptions = PreviewOptions{
+	Format:          "webp",
+	SegmentCount:    4,
+	SegmentDuration: 1.5,
+	Width:           426,
+	Height:          240,
+}
+
+// previewExtension returns the file extension for a preview's container.
+func previewExtension(format string) string {
+	if format == "mp4" {
+		return ".mp4"
+	}
+	return ".webp"
+}
+
+// previewContentType returns the Content-Type for a preview's container.
+func previewContentType(format string) string {
+	if format == "mp4" {
+		return "video/mp4"
+	}
+	return "image/webp"
+}
+
+// ffprobeFormat mirrors the subset of `ffprobe -show_format` output needed
+// to determine a video's duration.
+type ffprobeFormat struct {
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+}
+
+// getVideoDuration runs ffprobe to determine a video's duration in seconds.
+func getVideoDuration(videoPath string) (float64, error) {
+	cmd := exec.Command(
+		"ffprobe",
+		"-v", "quiet",
+		"-print_format", "json",
+		"-show_format",
+		videoPath,
+	)
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return 0, fmt.Errorf("ffprobe failed: %v", err)
+	}
+
+	var probe ffprobeFormat
+	if err := json.Unmarshal(stdout.Bytes(), &probe); err != nil {
+		return 0, fmt.Errorf("failed to parse ffprobe output: %v", err)
+	}
+
+	duration, err := strconv.ParseFloat(probe.Format.Duration, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse duration %q: %v", probe.Format.Duration, err)
+	}
+
+	return duration, nil
+}
+
+// segmentOffsets returns opts.SegmentCount timestamps (in seconds) spread
+// evenly across duration, leaving room for a full opts.SegmentDuration clip
+// at each one.
+func segmentOffsets(duration float64, opts PreviewOptions) []float64 {
+	count := opts.SegmentCount
+	if count <= 0 {
+		count = 1
+	}
+	usable := duration - opts.SegmentDuration
+	if usable < 0 {
+		usable = 0
+	}
+
+	offsets := make([]float64, count)
+	for i := 0; i < count; i++ {
+		if count == 1 {
+			offsets[i] = 0
+			continue
+		}
+		offsets[i] = usable * float64(i) / float64(count-1)
+	}
+	return offsets
+}
+
+// extractPreviewSegments extracts opts.SegmentCount short clips from
+// videoPath into outputDir, returning their paths in order. Any segments
+// already extracted are cleaned up if a later one fails.
+func extractPreviewSegments(videoPath, outputDir, baseName string, opts PreviewOptions) ([]string, error) {
+	duration, err := getVideoDuration(videoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine video duration: %v", err)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("video has no measurable duration")
+	}
+
+	var segments []string
+	for i, offset := range segmentOffsets(duration, opts) {
+		segPath := filepath.Join(outputDir, fmt.Sprintf("%s.seg-%d.mp4", baseName, i))
+
+		cmd := exec.Command(
+			"ffmpeg",
+			"-ss", fmt.Sprintf("%.3f", offset),
+			"-i", videoPath,
+			"-t", fmt.Sprintf("%.3f", opts.SegmentDuration),
+			"-vf", fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease", opts.Width, opts.Height),
+			"-an",
+			"-y",
+			segPath,
+		)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			for _, s := range segments {
+				os.Remove(s)
+			}
+			return nil, fmt.Errorf("ffmpeg segment %d failed: %v, stderr: %s", i, err, stderr.String())
+		}
+		segments = append(segments, segPath)
+	}
+
+	return segments, nil
+}
+
+// concatPreviewSegments concatenates already-scaled/trimmed segments into a
+// single animated WebP or muted MP4 using ffmpeg's concat demuxer.
+func concatPreviewSegments(segments []string, outputDir, baseName string, opts PreviewOptions) (string, error) {
+	listPath := filepath.Join(outputDir, baseName+".concat.txt")
+	var list strings.Builder
+	for _, seg := range segments {
+		list.WriteString(fmt.Sprintf("file '%s'\n", seg))
+	}
+	if err := os.WriteFile(listPath, []byte(list.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write concat list: %v", err)
+	}
+	defer os.Remove(listPath)
+
+	outPath := filepath.Join(outputDir, baseName+".preview"+previewExtension(opts.Format))
+
+	args := []string{"-f", "concat", "-safe", "0", "-i", listPath}
+	switch opts.Format {
+	case "mp4":
+		args = append(args, "-c:v", "libx264", "-an", "-movflags", "+faststart")
+	default:
+		args = append(args, "-c:v", "libwebp_anim", "-loop", "0", "-an")
+	}
+	args = append(args, "-y", outPath)
+
+	cmd := exec.Command("ffmpeg", args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("ffmpeg concat failed: %v, stderr: %s", err, stderr.String())
+	}
+
+	return outPath, nil
+}
+
+// GeneratePreviewClip generates a hover-preview clip for a single video
+// using opts (or DefaultPreviewOptions, if opts.Format is empty), with no
+// progress reporting.
+func (s *Service) GeneratePreviewClip(videoPath string, opts PreviewOptions) error {
+	return s.GeneratePreviewClipWithProgress(videoPath, opts, nil)
+}
+
+// GeneratePreviewClipWithProgress samples opts.SegmentCount short segments
+// spread across videoPath's duration, concatenates them, and uploads the
+// result next to the video's thumbnail as basePath.preview.webp (or .mp4).
+func (s *Service) GeneratePreviewClipWithProgress(videoPath string, opts PreviewOptions, progressCb ProgressCallback) error {
+	sendProgress := func(step string, progress int) {
+		if progressCb != nil {
+			progressCb(step, progress)
+		}
+	}
+
+	if opts.Format == "" {
+		opts = DefaultPreviewOptions
+	}
+
+	sendProgress("Checking FFmpeg", 5)
+	if err := checkFFmpeg(); err != nil {
+		return fmt.Errorf("FFmpeg is required but not found: %v", err)
+	}
+
+	outputDir := filepath.Join(os.TempDir(), "video-gallery-thumbnails")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create storage client: %v", err)
+	}
+	defer client.Close()
+
+	bucket := client.Bucket(s.config.BucketName)
+
+	ext := filepath.Ext(videoPath)
+	basePath := videoPath[:len(videoPath)-len(ext)]
+	previewPath := basePath + ".preview" + previewExtension(opts.Format)
+
+	videoBaseName := getSafeFilename(videoPath)
+	baseName := getSafeFilename(basePath)
+
+	sendProgress("Downloading video", 20)
+	tmpVideoPath := filepath.Join(outputDir, videoBaseName)
+	if err := downloadFile(ctx, bucket, videoPath, tmpVideoPath); err != nil {
+		return fmt.Errorf("error downloading video: %v", err)
+	}
+	defer os.Remove(tmpVideoPath)
+
+	sendProgress("Extracting segments", 40)
+	segments, err := extractPreviewSegments(tmpVideoPath, outputDir, baseName, opts)
+	if err != nil {
+		return fmt.Errorf("error extracting preview segments: %v", err)
+	}
+	defer func() {
+		for _, seg := range segments {
+			os.Remove(seg)
+		}
+	}()
+
+	sendProgress("Concatenating preview", 70)
+	tmpPreviewPath, err := concatPreviewSegments(segments, outputDir, baseName, opts)
+	if err != nil {
+		return fmt.Errorf("error concatenating preview: %v", err)
+	}
+	defer os.Remove(tmpPreviewPath)
+
+	sendProgress("Uploading preview", 90)
+	if err := uploadFileWithMetadata(ctx, bucket, tmpPreviewPath, previewPath, previewContentType(opts.Format), nil); err != nil {
+		return fmt.Errorf("error uploading preview: %v", err)
+	}
+
+	s.videoCache.Flush()
+
+	sendProgress("Complete", 100)
+	return nil
+}
+
+// BulkGeneratePreviewClipsWithOptions generates hover-preview clips for all
+// videos missing one (or all of them, with opts.Force), pipelining
+// downloads, segment extraction, and uploads across opts.Workers goroutines
+// - mirroring BulkGenerateThumbnailsWithOptions's worker pool and
+// BulkProgress reporting.
+func (s *Service) BulkGeneratePreviewClipsWithOptions(ctx context.Context, previewOpts PreviewOptions, opts BulkOptions, progressCb BulkProgressCallback) (int, int, error) {
+	if previewOpts.Format == "" {
+		previewOpts = DefaultPreviewOptions
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultBulkWorkers
+	}
+
+	sendProgress := func(p BulkProgress) {
+		if progressCb != nil {
+			progressCb(p)
+		}
+	}
+
+	if err := checkFFmpeg(); err != nil {
+		return 0, 0, fmt.Errorf("FFmpeg is required but not found: %v", err)
+	}
+
+	outputDir := filepath.Join(os.TempDir(), "video-gallery-thumbnails")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return 0, 0, fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create storage client: %v", err)
+	}
+	defer client.Close()
+
+	bucket := client.Bucket(s.config.BucketName)
+
+	videoExtensions := []string{".mp4", ".m4v", ".webm", ".mov", ".avi"}
+	previewSuffix := ".preview" + previewExtension(previewOpts.Format)
+
+	sendProgress(BulkProgress{Stage: BulkStageListing})
+
+	previewsMap := make(map[string]bool)
+	it := bucket.Objects(ctx, nil)
+	for {
+		obj, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("error iterating objects: %v", err)
+		}
+		if strings.HasSuffix(obj.Name, previewSuffix) {
+			previewsMap[strings.TrimSuffix(obj.Name, previewSuffix)] = true
+		}
+	}
+
+	var videoPaths []string
+	it = bucket.Objects(ctx, nil)
+	for {
+		obj, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("error iterating objects: %v", err)
+		}
+
+		parts := strings.Split(obj.Name, "/")
+		if len(parts) != 3 || parts[2] == "" {
+			continue
+		}
+
+		filename := parts[2]
+		isVideo := false
+		for _, ext := range videoExtensions {
+			if strings.HasSuffix(filename, ext) {
+				isVideo = true
+				break
+			}
+		}
+		if !isVideo {
+			continue
+		}
+
+		basePath := obj.Name[:len(obj.Name)-len(filepath.Ext(obj.Name))]
+		if !opts.Force && previewsMap[basePath] {
+			continue
+		}
+		videoPaths = append(videoPaths, obj.Name)
+	}
+
+	total := len(videoPaths)
+	sendProgress(BulkProgress{Total: total, Stage: BulkStageListing})
+
+	var wipMu sync.Mutex
+	wip := make(map[int]string)
+	defer func() {
+		wipMu.Lock()
+		defer wipMu.Unlock()
+		for _, path := range wip {
+			if path != "" {
+				os.Remove(path)
+			}
+		}
+	}()
+
+	paths := make(chan string)
+	go func() {
+		defer close(paths)
+		for _, p := range videoPaths {
+			select {
+			case paths <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		statsMu           sync.Mutex
+		completed, failed int
+	)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		workerID := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for videoPath := range paths {
+				if ctx.Err() != nil {
+					return
+				}
+
+				err := s.bulkGeneratePreviewOne(ctx, bucket, outputDir, videoPath, previewOpts, workerID, &wipMu, wip, sendProgress)
+
+				statsMu.Lock()
+				if err != nil {
+					log.Printf("Error generating preview for %s: %v", videoPath, err)
+					failed++
+				} else {
+					completed++
+				}
+				snapshot := BulkProgress{Total: total, Completed: completed, Failed: failed, CurrentFile: videoPath, Stage: BulkStageDone}
+				statsMu.Unlock()
+
+				sendProgress(snapshot)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	s.videoCache.Flush()
+
+	if ctx.Err() != nil {
+		return completed, failed, ctx.Err()
+	}
+	return completed, failed, nil
+}
+
+// bulkGeneratePreviewOne downloads a single video, extracts and
+// concatenates its preview segments, and uploads the result, reporting each
+// stage through sendProgress. Its temp video path is recorded in wip for
+// the duration of the download so a cancelled run can remove it.
+func (s *Service) bulkGeneratePreviewOne(ctx context.Context, bucket *storage.BucketHandle, outputDir, videoPath string, opts PreviewOptions, workerID int, wipMu *sync.Mutex, wip map[int]string, sendProgress func(BulkProgress)) error {
+	ext := filepath.Ext(videoPath)
+	basePath := videoPath[:len(videoPath)-len(ext)]
+	previewPath := basePath + ".preview" + previewExtension(opts.Format)
+
+	baseName := fmt.Sprintf("w%d-%s", workerID, getSafeFilename(basePath))
+	tmpVideoPath := filepath.Join(outputDir, fmt.Sprintf("w%d-%s", workerID, getSafeFilename(videoPath)))
+
+	wipMu.Lock()
+	wip[workerID] = tmpVideoPath
+	wipMu.Unlock()
+	defer func() {
+		wipMu.Lock()
+		delete(wip, workerID)
+		wipMu.Unlock()
+		os.Remove(tmpVideoPath)
+	}()
+
+	sendProgress(BulkProgress{CurrentFile: videoPath, Stage: BulkStageDownloading})
+	if err := downloadFile(ctx, bucket, videoPath, tmpVideoPath); err != nil {
+		return fmt.Errorf("downloading video: %v", err)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	sendProgress(BulkProgress{CurrentFile: videoPath, Stage: BulkStageGenerating})
+	segments, err := extractPreviewSegments(tmpVideoPath, outputDir, baseName, opts)
+	if err != nil {
+		return fmt.Errorf("extracting preview segments: %v", err)
+	}
+	defer func() {
+		for _, seg := range segments {
+			os.Remove(seg)
+		}
+	}()
+
+	tmpPreviewPath, err := concatPreviewSegments(segments, outputDir, baseName, opts)
+	if err != nil {
+		return fmt.Errorf("concatenating preview: %v", err)
+	}
+	defer os.Remove(tmpPreviewPath)
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	sendProgress(BulkProgress{CurrentFile: videoPath, Stage: BulkStageUploading})
+	if err := uploadFileWithMetadata(ctx, bucket, tmpPreviewPath, previewPath, previewContentType(opts.Format), nil); err != nil {
+		return fmt.Errorf("uploading preview: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"video-gallery/pkg/config"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// Service bundles a Config with the shared video cache so methods like
+// FetchMoviePoster and GenerateThumbnailWithProgress can invalidate it
+// after a mutation.
+type Service struct {
+	config     *config.Config
+	videoCache *cache.Cache
+}
+
+// NewService creates a Service bound to the shared videoCache so its
+// invalidations are visible to the package-level gallery functions too.
+func NewService(cfg *config.Config) *Service {
+	return &Service{
+		config:     cfg,
+		videoCache: videoCache,
+	}
+}
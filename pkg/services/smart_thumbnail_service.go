@@ -0,0 +1,372 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+
+	"cloud.google.com/go/storage"
+)
+
+// SmartMode selects how GenerateThumbnailSmart picks its representative
+// frame.
+type SmartMode string
+
+const (
+	// SmartModeFixed extracts a single frame at a caller-supplied timestamp,
+	// matching the original "pick a frame at timeMs" behavior. It's the only
+	// mode where validateThumbnail's solid-color check still applies.
+	SmartModeFixed SmartMode = "fixed"
+	// SmartModeSmart samples candidate frames across the video, discards
+	// near-duplicates via perceptual hashing, and keeps the most
+	// "interesting" one by colorfulness and edge density.
+	SmartModeSmart SmartMode = "smart"
+	// SmartModeBlurHash behaves like SmartModeSmart but treats a successful
+	// blurhash computation as required rather than best-effort, since the
+	// caller is relying on it as an LQIP placeholder.
+	SmartModeBlurHash SmartMode = "blurhash"
+)
+
+// SmartOptions configures GenerateThumbnailSmart.
+type SmartOptions struct {
+	Mode SmartMode
+	// TimeMs is the frame offset used by SmartModeFixed; ignored otherwise.
+	TimeMs int
+}
+
+// smartCandidateOffsets are the points (in seconds) sampled when looking for
+// a representative frame: every second for the first minute, then decimated
+// to one every ten seconds out to five minutes.
+func smartCandidateOffsets(duration float64) []float64 {
+	var offsets []float64
+	for t := 1.0; t < duration && t <= 60; t += 1.0 {
+		offsets = append(offsets, t)
+	}
+	for t := 70.0; t < duration && t <= 300; t += 10.0 {
+		offsets = append(offsets, t)
+	}
+	if len(offsets) == 0 {
+		offsets = append(offsets, duration/2)
+	}
+	return offsets
+}
+
+// dHashSize is the side length of the grayscale grid dHash is computed
+// over; a (dHashSize+1) x dHashSize grid of luminance comparisons yields a
+// dHashSize*dHashSize-bit hash.
+const dHashSize = 8
+
+// dHashDuplicateThreshold is the maximum Hamming distance, out of the
+// dHashSize*dHashSize bits in a dHash, for two frames to be considered
+// near-duplicates.
+const dHashDuplicateThreshold = 4
+
+// dHash computes a difference hash: for each row, whether each pixel is
+// darker than the one to its right, read off as a bit. Similar frames -
+// even after recompression - tend to produce hashes only a few bits apart.
+func dHash(img image.Image) uint64 {
+	grid := resizeGray(img, dHashSize+1, dHashSize)
+
+	var hash uint64
+	bit := uint(0)
+	for y := 0; y < dHashSize; y++ {
+		for x := 0; x < dHashSize; x++ {
+			if grid[y][x] < grid[y][x+1] {
+				hash |= 1 << bit
+			}
+			bit++
+		}
+	}
+	return hash
+}
+
+// resizeGray downsamples img to a width x height grid of luminance values
+// via nearest-neighbor sampling - enough precision for a perceptual hash
+// without pulling in a resampling dependency.
+func resizeGray(img image.Image, width, height int) [][]int {
+	bounds := img.Bounds()
+	grid := make([][]int, height)
+	for y := 0; y < height; y++ {
+		grid[y] = make([]int, width)
+		srcY := bounds.Min.Y + y*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			srcX := bounds.Min.X + x*bounds.Dx()/width
+			grid[y][x] = luminanceAt(img, srcX, srcY)
+		}
+	}
+	return grid
+}
+
+// hammingDistance64 counts the differing bits between two hashes.
+func hammingDistance64(a, b uint64) int {
+	x := a ^ b
+	count := 0
+	for x != 0 {
+		x &= x - 1
+		count++
+	}
+	return count
+}
+
+// luminanceAt returns the 0-255 luminance of the pixel at (x, y).
+func luminanceAt(img image.Image, x, y int) int {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return int(0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8))
+}
+
+// colorfulness implements Hasler & Süsstrunk's colorfulness metric - the
+// combined standard deviation and mean of the rg/yb opponent color channels
+// - rewarding frames with varied, saturated color over flat or grayscale
+// ones.
+func colorfulness(img image.Image) float64 {
+	bounds := img.Bounds()
+	var rgValues, ybValues []float64
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			rf, gf, bf := float64(r>>8), float64(g>>8), float64(b>>8)
+			rgValues = append(rgValues, rf-gf)
+			ybValues = append(ybValues, 0.5*(rf+gf)-bf)
+		}
+	}
+
+	rgMean, rgStd := meanStdDev(rgValues)
+	ybMean, ybStd := meanStdDev(ybValues)
+
+	stdRoot := math.Sqrt(rgStd*rgStd + ybStd*ybStd)
+	meanRoot := math.Sqrt(rgMean*rgMean + ybMean*ybMean)
+	return stdRoot + 0.3*meanRoot
+}
+
+// meanStdDev returns the mean and standard deviation of values.
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(values))
+
+	return mean, math.Sqrt(variance)
+}
+
+// edgeDensity approximates detail level via a Sobel operator, returning the
+// mean gradient magnitude normalized to roughly the same scale as
+// colorfulness.
+func edgeDensity(img image.Image) float64 {
+	bounds := img.Bounds()
+	width := bounds.Dx()
+	height := bounds.Dy()
+	if width < 3 || height < 3 {
+		return 0
+	}
+
+	sum := 0.0
+	samples := 0
+	for y := bounds.Min.Y + 1; y < bounds.Max.Y-1; y++ {
+		for x := bounds.Min.X + 1; x < bounds.Max.X-1; x++ {
+			gx := -luminanceAt(img, x-1, y-1) - 2*luminanceAt(img, x-1, y) - luminanceAt(img, x-1, y+1) +
+				luminanceAt(img, x+1, y-1) + 2*luminanceAt(img, x+1, y) + luminanceAt(img, x+1, y+1)
+			gy := -luminanceAt(img, x-1, y-1) - 2*luminanceAt(img, x, y-1) - luminanceAt(img, x+1, y-1) +
+				luminanceAt(img, x-1, y+1) + 2*luminanceAt(img, x, y+1) + luminanceAt(img, x+1, y+1)
+			sum += math.Sqrt(float64(gx*gx + gy*gy))
+			samples++
+		}
+	}
+
+	if samples == 0 {
+		return 0
+	}
+	return (sum / float64(samples)) / 10
+}
+
+// smartScore combines colorfulness with edge density into a single
+// "interestingness" score, favoring vivid, detailed frames over flat or
+// near-solid ones.
+func smartScore(img image.Image) float64 {
+	return colorfulness(img) + edgeDensity(img)
+}
+
+// decodeImage opens and decodes an image file at path.
+func decodeImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// pickSmartFrame extracts candidate frames spread across videoPath's
+// duration, discards near-duplicates (and, transitively, runs of solid or
+// near-solid frames) via dHash clustering, and returns the path of the
+// surviving candidate with the highest smartScore. Every non-winning
+// candidate is removed before returning.
+func pickSmartFrame(videoPath, thumbnailPath string) (string, error) {
+	duration, err := getVideoDuration(videoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine video duration: %v", err)
+	}
+
+	type candidate struct {
+		path  string
+		hash  uint64
+		score float64
+	}
+
+	var kept []candidate
+	for i, offset := range smartCandidateOffsets(duration) {
+		candidatePath := fmt.Sprintf("%s.candidate-%d.jpg", thumbnailPath, i)
+		if err := createThumbnailWithFFmpeg(videoPath, candidatePath, int(offset*1000)); err != nil {
+			continue
+		}
+
+		img, err := decodeImage(candidatePath)
+		if err != nil {
+			os.Remove(candidatePath)
+			continue
+		}
+
+		hash := dHash(img)
+
+		duplicate := false
+		for _, k := range kept {
+			if hammingDistance64(hash, k.hash) <= dHashDuplicateThreshold {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			os.Remove(candidatePath)
+			continue
+		}
+
+		kept = append(kept, candidate{path: candidatePath, hash: hash, score: smartScore(img)})
+	}
+
+	if len(kept) == 0 {
+		return "", fmt.Errorf("no usable candidate frames found")
+	}
+
+	best := kept[0]
+	for _, c := range kept[1:] {
+		if c.score > best.score {
+			best = c
+		}
+	}
+
+	for _, c := range kept {
+		if c.path != best.path {
+			os.Remove(c.path)
+		}
+	}
+
+	return best.path, nil
+}
+
+// GenerateThumbnailSmart generates a thumbnail using opts.Mode to decide how
+// the representative frame is chosen: SmartModeFixed extracts a single
+// frame at opts.TimeMs (the original "pick a frame at timeMs" behavior),
+// while SmartModeSmart and SmartModeBlurHash sample candidates across the
+// video and keep the most "interesting" one via perceptual-hash
+// deduplication and colorfulness/edge-density scoring - which makes
+// validateThumbnail's solid-color check redundant for those two modes.
+// SmartModeBlurHash additionally treats blurhash computation as required
+// rather than best-effort, since the caller is relying on it as an LQIP
+// placeholder.
+func (s *Service) GenerateThumbnailSmart(videoPath string, opts SmartOptions) error {
+	if err := checkFFmpeg(); err != nil {
+		return fmt.Errorf("FFmpeg is required but not found: %v", err)
+	}
+
+	outputDir := filepath.Join(os.TempDir(), "video-gallery-thumbnails")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create storage client: %v", err)
+	}
+	defer client.Close()
+
+	bucket := client.Bucket(s.config.BucketName)
+
+	ext := filepath.Ext(videoPath)
+	basePath := videoPath[:len(videoPath)-len(ext)]
+	thumbnailPath := basePath + ".jpg"
+
+	videoBaseName := getSafeFilename(videoPath)
+	thumbnailBaseName := getSafeFilename(thumbnailPath)
+
+	bucket.Object(thumbnailPath).Delete(ctx)
+
+	tmpVideoPath := filepath.Join(outputDir, videoBaseName)
+	if err := downloadFile(ctx, bucket, videoPath, tmpVideoPath); err != nil {
+		return fmt.Errorf("error downloading video: %v", err)
+	}
+	defer os.Remove(tmpVideoPath)
+
+	tmpThumbnailPath := filepath.Join(outputDir, thumbnailBaseName)
+	defer os.Remove(tmpThumbnailPath)
+
+	switch opts.Mode {
+	case SmartModeSmart, SmartModeBlurHash:
+		bestPath, err := pickSmartFrame(tmpVideoPath, tmpThumbnailPath)
+		if err != nil {
+			return fmt.Errorf("error picking smart frame: %v", err)
+		}
+		defer os.Remove(bestPath)
+
+		data, err := os.ReadFile(bestPath)
+		if err != nil {
+			return fmt.Errorf("failed to read best candidate: %v", err)
+		}
+		if err := os.WriteFile(tmpThumbnailPath, data, 0644); err != nil {
+			return fmt.Errorf("failed to write thumbnail: %v", err)
+		}
+
+	default:
+		if err := createThumbnailWithFFmpeg(tmpVideoPath, tmpThumbnailPath, opts.TimeMs); err != nil {
+			return fmt.Errorf("error creating thumbnail: %v", err)
+		}
+
+		if err := validateThumbnail(tmpThumbnailPath); err != nil {
+			return fmt.Errorf("thumbnail validation failed: %v", err)
+		}
+	}
+
+	if opts.Mode == SmartModeBlurHash {
+		hash, err := ComputeBlurhash(tmpThumbnailPath)
+		if err != nil {
+			return fmt.Errorf("error computing blurhash: %v", err)
+		}
+		metadata := map[string]string{"blurhash": hash}
+		if err := uploadFileWithMetadata(ctx, bucket, tmpThumbnailPath, thumbnailPath, contentTypeForVariantFormat(formatForPath(thumbnailPath)), metadata); err != nil {
+			return fmt.Errorf("error uploading thumbnail: %v", err)
+		}
+	} else {
+		if err := uploadThumbnailWithBlurhash(ctx, bucket, tmpThumbnailPath, thumbnailPath); err != nil {
+			return fmt.Errorf("error uploading thumbnail: %v", err)
+		}
+	}
+
+	s.videoCache.Flush()
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"cloud.google.com/go/storage"
+)
+
+// SourceStrategy selects how a video's bytes reach ffmpeg when extracting a
+// thumbnail frame, trading off local disk/memory use, bandwidth, and seek
+// accuracy.
+type SourceStrategy string
+
+const (
+	// SourceDownloadFull downloads the entire video to local disk before
+	// running ffmpeg against it - the original, simplest behavior, and the
+	// only strategy that's as fast to seek within as a local file.
+	SourceDownloadFull SourceStrategy = "download"
+	// SourceStreamStdin pipes the video directly from GCS into ffmpeg's
+	// stdin, avoiding the local copy at the cost of a linear scan to seek
+	// (a pipe has no random access).
+	SourceStreamStdin SourceStrategy = "stream"
+	// SourceSignedURL hands ffmpeg a short-lived signed URL as its input,
+	// letting its HTTP demuxer issue range requests so `-ss` seeks land
+	// accurately without downloading the bytes in between.
+	SourceSignedURL SourceStrategy = "signed-url"
+)
+
+// signedURLExpiry is how long a SourceSignedURL thumbnail extraction's
+// signed URL remains valid - comfortably longer than a single-frame ffmpeg
+// invocation should ever take.
+const signedURLExpiry = 10 * time.Minute
+
+// GenerateThumbnailWithSource behaves like GenerateThumbnailWithProgress but
+// lets the caller pick how the video reaches ffmpeg via strategy, so large
+// videos don't have to be downloaded in full just to extract one frame.
+func (s *Service) GenerateThumbnailWithSource(videoPath string, timeMs int, strategy SourceStrategy, progressCb ProgressCallback) error {
+	sendProgress := func(step string, progress int) {
+		if progressCb != nil {
+			progressCb(step, progress)
+		}
+	}
+
+	sendProgress("Checking FFmpeg", 5)
+	if err := checkFFmpeg(); err != nil {
+		return fmt.Errorf("FFmpeg is required but not found: %v", err)
+	}
+
+	sendProgress("Setting up directories", 10)
+	outputDir := filepath.Join(os.TempDir(), "video-gallery-thumbnails")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	sendProgress("Connecting to storage", 15)
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create storage client: %v", err)
+	}
+	defer client.Close()
+
+	bucket := client.Bucket(s.config.BucketName)
+
+	ext := filepath.Ext(videoPath)
+	basePath := videoPath[:len(videoPath)-len(ext)]
+	thumbnailPath := basePath + ".jpg"
+	thumbnailBaseName := getSafeFilename(thumbnailPath)
+
+	videoAttrs, err := bucket.Object(videoPath).Attrs(ctx)
+	if err != nil {
+		return fmt.Errorf("error reading video attrs: %v", err)
+	}
+
+	sendProgress("Clearing old thumbnail", 20)
+	bucket.Object(thumbnailPath).Delete(ctx)
+
+	tmpThumbnailPath := filepath.Join(outputDir, thumbnailBaseName)
+
+	sendProgress("Generating thumbnail", 60)
+	switch strategy {
+	case SourceStreamStdin:
+		if err := createThumbnailStreamed(ctx, bucket, videoPath, tmpThumbnailPath, timeMs); err != nil {
+			return fmt.Errorf("error creating thumbnail: %v", err)
+		}
+	case SourceSignedURL:
+		if err := createThumbnailSignedURL(ctx, bucket, videoPath, tmpThumbnailPath, timeMs); err != nil {
+			return fmt.Errorf("error creating thumbnail: %v", err)
+		}
+	default:
+		videoBaseName := getSafeFilename(videoPath)
+		tmpVideoPath := filepath.Join(outputDir, videoBaseName)
+
+		sendProgress("Downloading video", 30)
+		if err := downloadFile(ctx, bucket, videoPath, tmpVideoPath); err != nil {
+			return fmt.Errorf("error downloading video: %v", err)
+		}
+		defer os.Remove(tmpVideoPath)
+
+		if err := createThumbnailWithFFmpeg(tmpVideoPath, tmpThumbnailPath, timeMs); err != nil {
+			return fmt.Errorf("error creating thumbnail: %v", err)
+		}
+	}
+	defer os.Remove(tmpThumbnailPath)
+
+	sendProgress("Validating thumbnail", 80)
+	if err := validateThumbnail(tmpThumbnailPath); err != nil {
+		return fmt.Errorf("thumbnail validation failed: %v", err)
+	}
+
+	sendProgress("Uploading thumbnail", 85)
+	if err := uploadThumbnailWithBlurhash(ctx, bucket, tmpThumbnailPath, thumbnailPath); err != nil {
+		return fmt.Errorf("error uploading thumbnail: %v", err)
+	}
+
+	if cacheDB, err := openThumbnailCache(s.config); err != nil {
+		log.Printf("Warning: thumbnail cache unavailable, skipping cache update: %v", err)
+	} else {
+		recordThumbnailCache(ctx, cacheDB, bucket, videoAttrs, thumbnailPath, legacyThumbnailProfileHash(timeMs))
+		cacheDB.Close()
+	}
+
+	sendProgress("Clearing cache", 95)
+	s.videoCache.Flush()
+
+	sendProgress("Complete", 100)
+	return nil
+}
+
+// createThumbnailStreamed extracts a single frame at timeMs by piping src's
+// bytes directly into ffmpeg's stdin, never writing the source video to
+// local disk. Because a pipe has no random access, `-ss` before `pipe:0`
+// makes ffmpeg decode-and-discard up to timeMs rather than seeking via a
+// keyframe index, so it's slower than the other two strategies on a large
+// offset.
+func createThumbnailStreamed(ctx context.Context, bucket *storage.BucketHandle, src, thumbnailPath string, timeMs int) error {
+	reader, err := bucket.Object(src).NewReader(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open reader for %s: %v", src, err)
+	}
+	defer reader.Close()
+
+	seconds := timeMs / 1000
+	milliseconds := timeMs % 1000
+	timeStr := fmt.Sprintf("00:00:%02d.%03d", seconds, milliseconds)
+
+	cmd := exec.CommandContext(ctx,
+		"ffmpeg",
+		"-ss", timeStr,
+		"-i", "pipe:0",
+		"-frames:v", "1",
+		"-q:v", "2",
+		"-y",
+		thumbnailPath,
+	)
+	cmd.Stdin = reader
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %v", err)
+	}
+	return nil
+}
+
+// createThumbnailSignedURL extracts a single frame at timeMs by handing
+// ffmpeg a short-lived signed URL directly as its input. Unlike stdin
+// streaming, ffmpeg's HTTP demuxer can issue range requests against the
+// URL, so `-ss` before `-i` seeks via range request instead of a linear
+// scan.
+func createThumbnailSignedURL(ctx context.Context, bucket *storage.BucketHandle, src, thumbnailPath string, timeMs int) error {
+	url, err := bucket.SignedURL(src, &storage.SignedURLOptions{
+		Method:  "GET",
+		Expires: time.Now().Add(signedURLExpiry),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sign URL for %s: %v", src, err)
+	}
+
+	seconds := timeMs / 1000
+	milliseconds := timeMs % 1000
+	timeStr := fmt.Sprintf("00:00:%02d.%03d", seconds, milliseconds)
+
+	cmd := exec.CommandContext(ctx,
+		"ffmpeg",
+		"-ss", timeStr,
+		"-i", url,
+		"-frames:v", "1",
+		"-q:v", "2",
+		"-y",
+		thumbnailPath,
+	)
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %v", err)
+	}
+	return nil
+}
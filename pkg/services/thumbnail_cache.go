@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"cloud.google.com/go/storage"
+	bolt "go.etcd.io/bbolt"
+
+	"video-gallery/pkg/config"
+)
+
+// thumbnailCacheBucket is the single BoltDB bucket used to store
+// thumbnailCacheEntry records, keyed by video object name.
+const thumbnailCacheBucket = "thumbnails"
+
+// thumbnailCacheEntry records enough about the source video and the
+// profile used to generate its thumbnail that a later run can tell,
+// without touching ffmpeg, whether the existing thumbnail is still current.
+type thumbnailCacheEntry struct {
+	Generation          int64  `json:"generation"`
+	Crc32c              uint32 `json:"crc32c"`
+	ThumbnailObjectName string `json:"thumbnailObjectName"`
+	ThumbnailGeneration int64  `json:"thumbnailGeneration"`
+	ProfileHash         string `json:"profileHash"`
+}
+
+// thumbnailCachePath returns the BoltDB file used to persist
+// thumbnailCacheEntry records, preferring cfg.ThumbnailCachePath and
+// falling back to a fixed path under os.TempDir() so repeated CLI
+// invocations share the same cache.
+func thumbnailCachePath(cfg *config.Config) string {
+	if cfg != nil && cfg.ThumbnailCachePath != "" {
+		return cfg.ThumbnailCachePath
+	}
+	return filepath.Join(os.TempDir(), "video-gallery-thumbnail-cache.db")
+}
+
+// openThumbnailCache opens (creating if necessary) the BoltDB file backing
+// the thumbnail cache. Callers must Close the returned db once done.
+func openThumbnailCache(cfg *config.Config) (*bolt.DB, error) {
+	path := thumbnailCachePath(cfg)
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open thumbnail cache %s: %v", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(thumbnailCacheBucket))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize thumbnail cache bucket: %v", err)
+	}
+
+	return db, nil
+}
+
+// profileHash returns a short, stable fingerprint of a ThumbnailProfile so
+// cache entries are invalidated automatically when quality/size settings
+// change.
+func profileHash(p ThumbnailProfile) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d|%d", p.Format, p.MaxWidth, p.MaxHeight, p.Quality)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// legacyThumbnailProfileHash is the profileHash equivalent for the legacy
+// single-frame basePath.jpg thumbnail, which isn't described by a
+// ThumbnailProfile - its only generation parameter is the frame offset.
+func legacyThumbnailProfileHash(timeMs int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("legacy|%d", timeMs)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// getThumbnailCacheEntry looks up videoObjectName's cache entry, if any. db
+// may be nil (e.g. the cache failed to open), in which case it always
+// misses.
+func getThumbnailCacheEntry(db *bolt.DB, videoObjectName string) (*thumbnailCacheEntry, bool) {
+	if db == nil {
+		return nil, false
+	}
+
+	var entry thumbnailCacheEntry
+	found := false
+
+	db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(thumbnailCacheBucket))
+		data := bucket.Get([]byte(videoObjectName))
+		if data == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil
+		}
+		found = true
+		return nil
+	})
+
+	if !found {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// putThumbnailCacheEntry persists videoObjectName's cache entry. db may be
+// nil, in which case this is a no-op.
+func putThumbnailCacheEntry(db *bolt.DB, videoObjectName string, entry thumbnailCacheEntry) error {
+	if db == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(thumbnailCacheBucket))
+		return bucket.Put([]byte(videoObjectName), data)
+	})
+}
+
+// thumbnailCacheHit reports whether videoAttrs still matches entry's
+// recorded generation/checksum and generation parameters, meaning the
+// existing thumbnail object is current and regeneration can be skipped.
+func thumbnailCacheHit(entry *thumbnailCacheEntry, videoAttrs *storage.ObjectAttrs, expectedProfileHash string) bool {
+	if entry == nil {
+		return false
+	}
+	return entry.Generation == videoAttrs.Generation &&
+		entry.Crc32c == videoAttrs.CRC32C &&
+		entry.ProfileHash == expectedProfileHash
+}
+
+// recordThumbnailCache stores a cache entry for videoAttrs describing the
+// thumbnail just uploaded to thumbnailObjectName. Failures are logged and
+// otherwise ignored - the cache is a performance optimization, not a
+// correctness requirement.
+func recordThumbnailCache(ctx context.Context, db *bolt.DB, bucket *storage.BucketHandle, videoAttrs *storage.ObjectAttrs, thumbnailObjectName, expectedProfileHash string) {
+	if db == nil {
+		return
+	}
+
+	thumbAttrs, err := bucket.Object(thumbnailObjectName).Attrs(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to read attrs for %s, skipping cache update: %v", thumbnailObjectName, err)
+		return
+	}
+
+	entry := thumbnailCacheEntry{
+		Generation:          videoAttrs.Generation,
+		Crc32c:              videoAttrs.CRC32C,
+		ThumbnailObjectName: thumbnailObjectName,
+		ThumbnailGeneration: thumbAttrs.Generation,
+		ProfileHash:         expectedProfileHash,
+	}
+
+	if err := putThumbnailCacheEntry(db, videoAttrs.Name, entry); err != nil {
+		log.Printf("Warning: failed to persist cache entry for %s: %v", videoAttrs.Name, err)
+	}
+}
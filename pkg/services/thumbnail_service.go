@@ -14,9 +14,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+
+	_ "golang.org/x/image/webp"
 
 	"cloud.google.com/go/storage"
+	bolt "go.etcd.io/bbolt"
 	"google.golang.org/api/iterator"
 )
 
@@ -72,6 +77,11 @@ func (s *Service) GenerateThumbnailWithProgress(videoPath string, timeMs int, pr
 	videoBaseName := getSafeFilename(videoPath)
 	thumbnailBaseName := getSafeFilename(thumbnailPath)
 
+	videoAttrs, err := bucket.Object(videoPath).Attrs(ctx)
+	if err != nil {
+		return fmt.Errorf("error reading video attrs: %v", err)
+	}
+
 	sendProgress("Clearing old thumbnail", 20)
 	bucket.Object(thumbnailPath).Delete(ctx)
 
@@ -84,7 +94,10 @@ func (s *Service) GenerateThumbnailWithProgress(videoPath string, timeMs int, pr
 
 	sendProgress("Generating thumbnail", 60)
 	tmpThumbnailPath := filepath.Join(outputDir, thumbnailBaseName)
-	if err := createThumbnailWithFFmpeg(tmpVideoPath, tmpThumbnailPath, timeMs); err != nil {
+	unlock := lockVideo(videoPath)
+	err = createThumbnailWithFFmpeg(tmpVideoPath, tmpThumbnailPath, timeMs)
+	unlock()
+	if err != nil {
 		return fmt.Errorf("error creating thumbnail: %v", err)
 	}
 	defer os.Remove(tmpThumbnailPath)
@@ -95,10 +108,24 @@ func (s *Service) GenerateThumbnailWithProgress(videoPath string, timeMs int, pr
 	}
 
 	sendProgress("Uploading thumbnail", 85)
-	if err := uploadFile(ctx, bucket, tmpThumbnailPath, thumbnailPath); err != nil {
+	if err := uploadThumbnailWithBlurhash(ctx, bucket, tmpThumbnailPath, thumbnailPath); err != nil {
 		return fmt.Errorf("error uploading thumbnail: %v", err)
 	}
 
+	sendProgress("Generating modern-format variants", 90)
+	uploadModernThumbnailVariants(ctx, bucket, outputDir, thumbnailBaseName, basePath, videoPath, tmpThumbnailPath)
+
+	if _, err := writeBlurhashSidecar(ctx, bucket, tmpThumbnailPath, thumbnailPath); err != nil {
+		log.Printf("Warning: failed to write blurhash sidecar for %s: %v", videoPath, err)
+	}
+
+	if cacheDB, err := openThumbnailCache(s.config); err != nil {
+		log.Printf("Warning: thumbnail cache unavailable, skipping cache update: %v", err)
+	} else {
+		recordThumbnailCache(ctx, cacheDB, bucket, videoAttrs, thumbnailPath, legacyThumbnailProfileHash(timeMs))
+		cacheDB.Close()
+	}
+
 	sendProgress("Clearing cache", 95)
 	s.videoCache.Flush()
 
@@ -128,9 +155,74 @@ func (s *Service) ClearThumbnail(thumbnailPath string) error {
 	return nil
 }
 
-// BulkGenerateThumbnails generates thumbnails for all videos
+// defaultBulkWorkers is how many videos BulkGeneratePreviewClipsWithOptions
+// processes concurrently when BulkOptions.Workers isn't set.
+// BulkGenerateThumbnailsWithOptions instead defaults to runtime.NumCPU().
+const defaultBulkWorkers = 4
+
+// BulkOptions configures a BulkGenerateThumbnailsWithOptions run.
+type BulkOptions struct {
+	Workers int  // videos to process concurrently (defaults to runtime.NumCPU())
+	TimeMs  int  // time offset, in milliseconds, to extract the frame at
+	Force   bool // regenerate thumbnails that already exist
+}
+
+// BulkStage identifies which step of the per-video pipeline a BulkProgress
+// update refers to.
+type BulkStage string
+
+const (
+	BulkStageListing     BulkStage = "listing"
+	BulkStageDownloading BulkStage = "downloading"
+	BulkStageGenerating  BulkStage = "generating"
+	BulkStageValidating  BulkStage = "validating"
+	BulkStageUploading   BulkStage = "uploading"
+	BulkStageBackfilling BulkStage = "backfilling"
+	BulkStageDone        BulkStage = "done"
+)
+
+// BulkProgress is a single progress frame emitted by
+// BulkGenerateThumbnailsWithOptions, reporting both the aggregate run state
+// and the item currently in flight. It may arrive concurrently from
+// multiple workers, so CurrentFile/Stage reflect whichever worker last
+// reported in, not a single global cursor.
+type BulkProgress struct {
+	Total       int
+	Completed   int
+	Failed      int
+	CurrentFile string
+	Stage       BulkStage
+}
+
+// BulkProgressCallback receives a BulkProgress update after each pipeline
+// stage transition.
+type BulkProgressCallback func(BulkProgress)
+
+// BulkGenerateThumbnails generates thumbnails for all videos missing one,
+// using the default worker count and no progress reporting.
 func (s *Service) BulkGenerateThumbnails(timeMs int, force bool) (int, int, error) {
-	// Check if ffmpeg is installed
+	return s.BulkGenerateThumbnailsWithOptions(context.Background(), BulkOptions{TimeMs: timeMs, Force: force}, nil)
+}
+
+// BulkGenerateThumbnailsWithOptions generates thumbnails for all videos
+// missing one or whose thumbnail cache entry doesn't show it up to date (or
+// all of them, with opts.Force), pipelining bucket listing,
+// download, ffmpeg extraction, validation, and upload across a bounded pool
+// of opts.Workers goroutines. Cancelling ctx (e.g. via signal.NotifyContext
+// on SIGINT) stops in-flight work and removes each worker's temp files
+// before returning.
+func (s *Service) BulkGenerateThumbnailsWithOptions(ctx context.Context, opts BulkOptions, progressCb BulkProgressCallback) (int, int, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	sendProgress := func(p BulkProgress) {
+		if progressCb != nil {
+			progressCb(p)
+		}
+	}
+
 	if err := checkFFmpeg(); err != nil {
 		return 0, 0, fmt.Errorf("FFmpeg is required but not found: %v", err)
 	}
@@ -140,7 +232,6 @@ func (s *Service) BulkGenerateThumbnails(timeMs int, force bool) (int, int, erro
 		return 0, 0, fmt.Errorf("failed to create output directory: %v", err)
 	}
 
-	ctx := context.Background()
 	client, err := storage.NewClient(ctx)
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to create storage client: %v", err)
@@ -149,13 +240,22 @@ func (s *Service) BulkGenerateThumbnails(timeMs int, force bool) (int, int, erro
 
 	bucket := client.Bucket(s.config.BucketName)
 
+	cacheDB, err := openThumbnailCache(s.config)
+	if err != nil {
+		log.Printf("Warning: thumbnail cache unavailable, regenerating unconditionally: %v", err)
+		cacheDB = nil
+	} else {
+		defer cacheDB.Close()
+	}
+	legacyHash := legacyThumbnailProfileHash(opts.TimeMs)
+
 	videoExtensions := []string{".mp4", ".m4v", ".webm", ".mov", ".avi"}
 	imageExtensions := []string{".jpg", ".jpeg", ".png"}
 
-	// Map to track which videos have thumbnails
-	thumbnailsMap := make(map[string]bool)
+	sendProgress(BulkProgress{Stage: BulkStageListing})
 
-	// First pass: find all thumbnails
+	// First pass: find all existing thumbnails
+	thumbnailsMap := make(map[string]bool)
 	it := bucket.Objects(ctx, nil)
 	for {
 		obj, err := it.Next()
@@ -172,32 +272,26 @@ func (s *Service) BulkGenerateThumbnails(timeMs int, force bool) (int, int, erro
 		}
 
 		filename := parts[2]
-		isImage := false
 		for _, ext := range imageExtensions {
 			if strings.HasSuffix(filename, ext) {
-				isImage = true
+				thumbnailsMap[obj.Name[:len(obj.Name)-len(filepath.Ext(obj.Name))]] = true
 				break
 			}
 		}
-
-		if isImage {
-			thumbnailsMap[obj.Name[:len(obj.Name)-len(filepath.Ext(obj.Name))]] = true
-		}
 	}
 
-	// Second pass: find all videos and generate thumbnails
+	// Second pass: collect videos that need a thumbnail into a work list,
+	// skipping ones whose cache entry shows the video hasn't changed since
+	// its thumbnail was last generated
+	var videoObjects []*storage.ObjectAttrs
 	it = bucket.Objects(ctx, nil)
-
-	totalProcessed := 0
-	totalErrors := 0
-
 	for {
 		obj, err := it.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return totalProcessed, totalErrors, fmt.Errorf("error iterating objects: %v", err)
+			return 0, 0, fmt.Errorf("error iterating objects: %v", err)
 		}
 
 		parts := strings.Split(obj.Name, "/")
@@ -213,69 +307,157 @@ func (s *Service) BulkGenerateThumbnails(timeMs int, force bool) (int, int, erro
 				break
 			}
 		}
-
 		if !isVideo {
 			continue
 		}
 
-		videoPath := obj.Name
-		basePath := videoPath[:len(videoPath)-len(filepath.Ext(videoPath))]
-		thumbnailNeeded := !thumbnailsMap[basePath] || force
-
-		if thumbnailNeeded {
-			thumbnailPath := basePath + ".jpg"
-
-			// Generate safe filenames
-			videoBaseName := getSafeFilename(videoPath)
-			thumbnailBaseName := getSafeFilename(thumbnailPath)
-
-			// Download video
-			tmpVideoPath := filepath.Join(outputDir, videoBaseName)
-			if err := downloadFile(ctx, bucket, videoPath, tmpVideoPath); err != nil {
-				log.Printf("Error downloading video %s: %v", videoPath, err)
-				totalErrors++
+		basePath := obj.Name[:len(obj.Name)-len(filepath.Ext(obj.Name))]
+		if !opts.Force && thumbnailsMap[basePath] {
+			entry, _ := getThumbnailCacheEntry(cacheDB, obj.Name)
+			if thumbnailCacheHit(entry, obj, legacyHash) {
 				continue
 			}
-
-			// Create thumbnail
-			tmpThumbnailPath := filepath.Join(outputDir, thumbnailBaseName)
-			if err := createThumbnailWithFFmpeg(tmpVideoPath, tmpThumbnailPath, timeMs); err != nil {
-				log.Printf("Error creating thumbnail for %s: %v", videoPath, err)
-				os.Remove(tmpVideoPath)
-				totalErrors++
-				continue
+		}
+		videoObjects = append(videoObjects, obj)
+	}
+
+	total := len(videoObjects)
+	sendProgress(BulkProgress{Total: total, Stage: BulkStageListing})
+
+	// wip tracks each worker's in-flight temp video path, keyed by worker
+	// ID, so a cancelled run can clean them all up - mirroring fastgallery's
+	// WIP-jobs mutex pattern.
+	var wipMu sync.Mutex
+	wip := make(map[int]string)
+	defer func() {
+		wipMu.Lock()
+		defer wipMu.Unlock()
+		for _, path := range wip {
+			if path != "" {
+				os.Remove(path)
 			}
-
-			// Validate thumbnail
-			if err := validateThumbnail(tmpThumbnailPath); err != nil {
-				log.Printf("Thumbnail validation failed for %s: %v", videoPath, err)
-				os.Remove(tmpVideoPath)
-				os.Remove(tmpThumbnailPath)
-				totalErrors++
-				continue
+		}
+	}()
+
+	paths := make(chan *storage.ObjectAttrs)
+	go func() {
+		defer close(paths)
+		for _, o := range videoObjects {
+			select {
+			case paths <- o:
+			case <-ctx.Done():
+				return
 			}
+		}
+	}()
 
-			// Upload thumbnail
-			if err := uploadFile(ctx, bucket, tmpThumbnailPath, thumbnailPath); err != nil {
-				log.Printf("Error uploading thumbnail for %s: %v", videoPath, err)
-				os.Remove(tmpVideoPath)
-				os.Remove(tmpThumbnailPath)
-				totalErrors++
-				continue
+	var (
+		statsMu           sync.Mutex
+		completed, failed int
+	)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		workerID := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for videoAttrs := range paths {
+				if ctx.Err() != nil {
+					return
+				}
+
+				err := s.bulkGenerateOne(ctx, bucket, outputDir, videoAttrs, opts.TimeMs, workerID, &wipMu, wip, sendProgress, cacheDB, legacyHash)
+
+				statsMu.Lock()
+				if err != nil {
+					log.Printf("Error generating thumbnail for %s: %v", videoAttrs.Name, err)
+					failed++
+				} else {
+					completed++
+				}
+				snapshot := BulkProgress{Total: total, Completed: completed, Failed: failed, CurrentFile: videoAttrs.Name, Stage: BulkStageDone}
+				statsMu.Unlock()
+
+				sendProgress(snapshot)
 			}
+		}()
+	}
 
-			totalProcessed++
+	wg.Wait()
 
-			// Clean up
-			os.Remove(tmpVideoPath)
-			os.Remove(tmpThumbnailPath)
-		}
+	s.videoCache.Flush()
+
+	if ctx.Err() != nil {
+		return completed, failed, ctx.Err()
 	}
+	return completed, failed, nil
+}
 
-	// Clear cache
-	s.videoCache.Flush()
+// bulkGenerateOne downloads a single video, extracts and validates a
+// thumbnail frame, and uploads it, reporting each stage through
+// sendProgress. Its temp video path is recorded in wip for the duration of
+// the download so a cancelled run can remove it. On success, it records a
+// cache entry so a later run can skip regeneration if the video and
+// generation parameters haven't changed.
+func (s *Service) bulkGenerateOne(ctx context.Context, bucket *storage.BucketHandle, outputDir string, videoAttrs *storage.ObjectAttrs, timeMs, workerID int, wipMu *sync.Mutex, wip map[int]string, sendProgress func(BulkProgress), cacheDB *bolt.DB, expectedProfileHash string) error {
+	videoPath := videoAttrs.Name
+	basePath := videoPath[:len(videoPath)-len(filepath.Ext(videoPath))]
+	thumbnailPath := basePath + ".jpg"
 
-	return totalProcessed, totalErrors, nil
+	videoBaseName := getSafeFilename(videoPath)
+	thumbnailBaseName := getSafeFilename(thumbnailPath)
+
+	tmpVideoPath := filepath.Join(outputDir, fmt.Sprintf("w%d-%s", workerID, videoBaseName))
+	tmpThumbnailPath := filepath.Join(outputDir, fmt.Sprintf("w%d-%s", workerID, thumbnailBaseName))
+
+	wipMu.Lock()
+	wip[workerID] = tmpVideoPath
+	wipMu.Unlock()
+	defer func() {
+		wipMu.Lock()
+		delete(wip, workerID)
+		wipMu.Unlock()
+		os.Remove(tmpVideoPath)
+		os.Remove(tmpThumbnailPath)
+	}()
+
+	sendProgress(BulkProgress{CurrentFile: videoPath, Stage: BulkStageDownloading})
+	if err := downloadFile(ctx, bucket, videoPath, tmpVideoPath); err != nil {
+		return fmt.Errorf("downloading video: %v", err)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	sendProgress(BulkProgress{CurrentFile: videoPath, Stage: BulkStageGenerating})
+	unlock := lockVideo(videoPath)
+	err := createThumbnailWithFFmpeg(tmpVideoPath, tmpThumbnailPath, timeMs)
+	unlock()
+	if err != nil {
+		return fmt.Errorf("creating thumbnail: %v", err)
+	}
+
+	sendProgress(BulkProgress{CurrentFile: videoPath, Stage: BulkStageValidating})
+	if err := validateThumbnail(tmpThumbnailPath); err != nil {
+		return fmt.Errorf("thumbnail validation failed: %v", err)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	sendProgress(BulkProgress{CurrentFile: videoPath, Stage: BulkStageUploading})
+	if err := uploadThumbnailWithBlurhash(ctx, bucket, tmpThumbnailPath, thumbnailPath); err != nil {
+		return fmt.Errorf("uploading thumbnail: %v", err)
+	}
+	uploadModernThumbnailVariants(ctx, bucket, outputDir, fmt.Sprintf("w%d-%s", workerID, thumbnailBaseName), basePath, videoPath, tmpThumbnailPath)
+	if _, err := writeBlurhashSidecar(ctx, bucket, tmpThumbnailPath, thumbnailPath); err != nil {
+		log.Printf("Warning: failed to write blurhash sidecar for %s: %v", videoPath, err)
+	}
+
+	recordThumbnailCache(ctx, cacheDB, bucket, videoAttrs, thumbnailPath, expectedProfileHash)
+
+	return nil
 }
 
 // BulkClearThumbnails removes all thumbnails from storage
@@ -416,10 +598,18 @@ func downloadFile(ctx context.Context, bucket *storage.BucketHandle, src, dst st
 }
 
 func uploadFile(ctx context.Context, bucket *storage.BucketHandle, src, dst string) error {
-	data, err := os.ReadFile(src)
+	return uploadFileWithMetadata(ctx, bucket, src, dst, contentTypeForVariantFormat(formatForPath(dst)), nil)
+}
+
+// uploadFileWithMetadata uploads src to dst with the given Content-Type,
+// attaching metadata as GCS custom metadata on the resulting object (e.g. a
+// blurhash placeholder).
+func uploadFileWithMetadata(ctx context.Context, bucket *storage.BucketHandle, src, dst, contentType string, metadata map[string]string) error {
+	f, err := os.Open(src)
 	if err != nil {
-		return fmt.Errorf("os.ReadFile: %v", err)
+		return fmt.Errorf("os.Open: %v", err)
 	}
+	defer f.Close()
 
 	dst = strings.TrimPrefix(dst, "/")
 
@@ -428,10 +618,11 @@ func uploadFile(ctx context.Context, bucket *storage.BucketHandle, src, dst stri
 	}
 
 	writer := bucket.Object(dst).NewWriter(ctx)
-	writer.ContentType = "image/jpeg"
+	writer.ContentType = contentType
+	writer.Metadata = metadata
 
-	if _, err := writer.Write(data); err != nil {
-		return fmt.Errorf("Writer.Write: %v", err)
+	if _, err := io.Copy(writer, f); err != nil {
+		return fmt.Errorf("io.Copy: %v", err)
 	}
 
 	if err := writer.Close(); err != nil {
@@ -441,7 +632,266 @@ func uploadFile(ctx context.Context, bucket *storage.BucketHandle, src, dst stri
 	return nil
 }
 
+// uploadThumbnailWithBlurhash uploads the thumbnail at thumbnailPath to dst,
+// computing a blurhash placeholder and attaching it (plus the dimensions it
+// was computed from) as custom metadata so GetVideos can surface them
+// straight off the bucket listing, without fetching anything per-thumbnail.
+func uploadThumbnailWithBlurhash(ctx context.Context, bucket *storage.BucketHandle, thumbnailPath, dst string) error {
+	metadata := map[string]string{}
+	if hash, width, height, err := ComputeBlurhashWithDims(thumbnailPath); err != nil {
+		log.Printf("Warning: failed to compute blurhash for %s: %v", thumbnailPath, err)
+	} else {
+		metadata = EncodeBlurhashMetadata(hash, width, height)
+	}
+
+	return uploadFileWithMetadata(ctx, bucket, thumbnailPath, dst, contentTypeForVariantFormat(formatForPath(dst)), metadata)
+}
+
+// ThumbnailProfile describes one thumbnail variant to generate: its output
+// format, the box it should fit within, and (for lossy formats) its
+// encoding quality.
+type ThumbnailProfile struct {
+	Format    string // "jpeg", "webp", or "avif"
+	MaxWidth  int
+	MaxHeight int
+	Quality   int // ffmpeg's -q:v scale (lower is better); 0 uses the encoder default
+}
+
+// variantName returns the object name a profile's output is uploaded under,
+// e.g. basePath + ".thumb-320.webp", so the gallery can serve responsive
+// <picture>/srcset markup without guessing at variant filenames.
+func (p ThumbnailProfile) variantName(basePath string) string {
+	return fmt.Sprintf("%s.thumb-%d%s", basePath, p.MaxWidth, extensionForVariantFormat(p.Format))
+}
+
+// DefaultThumbnailProfiles are the variants GenerateThumbnailVariants
+// produces when the caller doesn't supply its own list: a 320px JPEG for
+// parity with the legacy single-thumbnail path, plus WebP at three
+// responsive widths.
+var DefaultThumbnailProfiles = []ThumbnailProfile{
+	{Format: "jpeg", MaxWidth: 320, MaxHeight: 200, Quality: 2},
+	{Format: "webp", MaxWidth: 320, MaxHeight: 200},
+	{Format: "webp", MaxWidth: 640, MaxHeight: 400},
+	{Format: "webp", MaxWidth: 1280, MaxHeight: 800},
+}
+
+// WebPThumbnailProfile and AVIFThumbnailProfile describe the modern-format
+// thumbnail variants GenerateThumbnailWithProgress and bulkGenerateOne emit
+// alongside the legacy JPEG, fitting the same bounding box. Both are
+// produced by a single ffmpeg invocation against the already-extracted
+// candidate frame (see uploadModernThumbnailVariants), so the source video
+// is never re-decoded to make them.
+var (
+	WebPThumbnailProfile = ThumbnailProfile{Format: "webp", MaxWidth: 320, MaxHeight: 200}
+	AVIFThumbnailProfile = ThumbnailProfile{Format: "avif", MaxWidth: 320, MaxHeight: 200}
+)
+
+// ThumbnailVariantPath returns the object name a videoPath's thumbnail
+// would be uploaded under for the given format ("webp", "avif", or
+// anything else for the legacy JPEG path), for handlers that need to look
+// a variant up without generating one.
+func ThumbnailVariantPath(videoPath, format string) string {
+	ext := filepath.Ext(videoPath)
+	basePath := videoPath[:len(videoPath)-len(ext)]
+	switch format {
+	case "webp":
+		return WebPThumbnailProfile.variantName(basePath)
+	case "avif":
+		return AVIFThumbnailProfile.variantName(basePath)
+	default:
+		return basePath + ".jpg"
+	}
+}
+
+// uploadModernThumbnailVariants re-encodes the already-generated JPEG
+// candidate frame at tmpThumbnailPath into WebP and AVIF in one ffmpeg
+// invocation and uploads both, logging (rather than failing the caller) on
+// error since these variants are a progressive enhancement over the JPEG
+// that's already been uploaded.
+func uploadModernThumbnailVariants(ctx context.Context, bucket *storage.BucketHandle, outputDir, thumbnailBaseName, basePath, videoPath, tmpThumbnailPath string) {
+	variants, err := convertThumbnailVariants(tmpThumbnailPath, outputDir, thumbnailBaseName, []ThumbnailProfile{WebPThumbnailProfile, AVIFThumbnailProfile})
+	if err != nil {
+		log.Printf("Warning: failed to generate WebP/AVIF thumbnail variants for %s: %v", videoPath, err)
+		return
+	}
+
+	for profile, localPath := range variants {
+		dst := profile.variantName(basePath)
+		if err := uploadFileWithMetadata(ctx, bucket, localPath, dst, contentTypeForVariantFormat(profile.Format), nil); err != nil {
+			log.Printf("Warning: failed to upload %s thumbnail variant for %s: %v", profile.Format, videoPath, err)
+		}
+		os.Remove(localPath)
+	}
+}
+
+// extensionForVariantFormat returns the file extension for a thumbnail
+// variant's output format.
+func extensionForVariantFormat(format string) string {
+	switch format {
+	case "webp":
+		return ".webp"
+	case "avif":
+		return ".avif"
+	default:
+		return ".jpg"
+	}
+}
+
+// contentTypeForVariantFormat returns the Content-Type for a thumbnail
+// variant's output format; any format other than "webp" or "avif", including
+// an empty one, maps to image/jpeg.
+func contentTypeForVariantFormat(format string) string {
+	switch format {
+	case "webp":
+		return "image/webp"
+	case "avif":
+		return "image/avif"
+	default:
+		return "image/jpeg"
+	}
+}
+
+// formatForPath infers a variant format ("jpeg", "webp", "avif") from an
+// object name's extension, for callers that only have a destination path.
+func formatForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".webp":
+		return "webp"
+	case ".avif":
+		return "avif"
+	default:
+		return "jpeg"
+	}
+}
+
+// convertThumbnailVariants re-encodes the candidate frame at srcPath into
+// every requested profile - different formats and/or resolutions - as a
+// single ffmpeg invocation with one "-vf scale=... -c:v ..." output group
+// per profile, rather than shelling out once per variant.
+func convertThumbnailVariants(srcPath, outputDir, baseName string, profiles []ThumbnailProfile) (map[ThumbnailProfile]string, error) {
+	args := []string{"-i", srcPath}
+	outputs := make(map[ThumbnailProfile]string, len(profiles))
+
+	for _, p := range profiles {
+		outPath := filepath.Join(outputDir, fmt.Sprintf("%s.thumb-%d%s", baseName, p.MaxWidth, extensionForVariantFormat(p.Format)))
+		outputs[p] = outPath
+
+		args = append(args, "-vf", fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease", p.MaxWidth, p.MaxHeight))
+		switch p.Format {
+		case "webp":
+			args = append(args, "-c:v", "libwebp")
+		case "avif":
+			args = append(args, "-c:v", "libaom-av1", "-still-picture", "1")
+		}
+		if p.Quality > 0 {
+			args = append(args, "-q:v", fmt.Sprintf("%d", p.Quality))
+		}
+		args = append(args, "-y", outPath)
+	}
+
+	cmd := exec.Command("ffmpeg", args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %v, stderr: %s", err, stderr.String())
+	}
+
+	return outputs, nil
+}
+
+// GenerateThumbnailVariants generates the given thumbnail profiles (or
+// DefaultThumbnailProfiles, if nil) for a single video, uploading each under
+// its predictable basePath.thumb-WIDTH.ext name, and returns the uploaded
+// object names.
+func (s *Service) GenerateThumbnailVariants(videoPath string, timeMs int, profiles []ThumbnailProfile) ([]string, error) {
+	if profiles == nil {
+		profiles = DefaultThumbnailProfiles
+	}
+
+	if err := checkFFmpeg(); err != nil {
+		return nil, fmt.Errorf("FFmpeg is required but not found: %v", err)
+	}
+
+	outputDir := filepath.Join(os.TempDir(), "video-gallery-thumbnails")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage client: %v", err)
+	}
+	defer client.Close()
+
+	bucket := client.Bucket(s.config.BucketName)
+
+	ext := filepath.Ext(videoPath)
+	basePath := videoPath[:len(videoPath)-len(ext)]
+
+	videoBaseName := getSafeFilename(videoPath)
+	candidateBaseName := getSafeFilename(basePath)
+
+	tmpVideoPath := filepath.Join(outputDir, videoBaseName)
+	if err := downloadFile(ctx, bucket, videoPath, tmpVideoPath); err != nil {
+		return nil, fmt.Errorf("error downloading video: %v", err)
+	}
+	defer os.Remove(tmpVideoPath)
+
+	candidatePath := filepath.Join(outputDir, candidateBaseName+".candidate.jpg")
+	if err := createThumbnailWithFFmpeg(tmpVideoPath, candidatePath, timeMs); err != nil {
+		return nil, fmt.Errorf("error creating thumbnail: %v", err)
+	}
+	defer os.Remove(candidatePath)
+
+	if err := validateThumbnail(candidatePath); err != nil {
+		return nil, fmt.Errorf("thumbnail validation failed: %v", err)
+	}
+
+	variants, err := convertThumbnailVariants(candidatePath, outputDir, candidateBaseName, profiles)
+	if err != nil {
+		return nil, fmt.Errorf("error converting thumbnail variants: %v", err)
+	}
+
+	var uploaded []string
+	for profile, localPath := range variants {
+		dst := profile.variantName(basePath)
+		contentType := contentTypeForVariantFormat(profile.Format)
+
+		var uploadErr error
+		if profile.Format == "jpeg" {
+			uploadErr = uploadThumbnailWithBlurhash(ctx, bucket, localPath, dst)
+		} else {
+			uploadErr = uploadFileWithMetadata(ctx, bucket, localPath, dst, contentType, nil)
+		}
+		os.Remove(localPath)
+
+		if uploadErr != nil {
+			return uploaded, fmt.Errorf("error uploading variant %s: %v", dst, uploadErr)
+		}
+		uploaded = append(uploaded, dst)
+	}
+
+	s.videoCache.Flush()
+
+	return uploaded, nil
+}
+
 func validateThumbnail(thumbnailPath string) error {
+	if strings.EqualFold(filepath.Ext(thumbnailPath), ".avif") {
+		// No readily available pure-Go AVIF decoder; fall back to a
+		// non-empty-file check rather than pixel sampling
+		info, err := os.Stat(thumbnailPath)
+		if err != nil {
+			return fmt.Errorf("failed to stat thumbnail: %v", err)
+		}
+		if info.Size() == 0 {
+			return fmt.Errorf("thumbnail is empty")
+		}
+		return nil
+	}
+
 	f, err := os.Open(thumbnailPath)
 	if err != nil {
 		return fmt.Errorf("failed to open thumbnail: %v", err)
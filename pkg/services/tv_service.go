@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cloud.google.com/go/storage"
+)
+
+const (
+	tmdbTVSearchURL = "https://api.themoviedb.org/3/search/tv"
+	tmdbTVBaseURL   = "https://api.themoviedb.org/3/tv"
+)
+
+// TMDbTVSearchResult represents a TV show search result from TMDb
+type TMDbTVSearchResult struct {
+	Results []struct {
+		ID         int     `json:"id"`
+		Name       string  `json:"name"`
+		PosterPath *string `json:"poster_path"`
+	} `json:"results"`
+}
+
+// TMDbSeasonResult represents a season (and its episode stills) from TMDb
+type TMDbSeasonResult struct {
+	PosterPath *string `json:"poster_path"`
+	Episodes   []struct {
+		EpisodeNumber int     `json:"episode_number"`
+		StillPath     *string `json:"still_path"`
+	} `json:"episodes"`
+}
+
+// FetchShowPoster looks up showName on TMDb and uploads the show poster,
+// then every season's poster and episode stills, as
+// "<category>/<showName>.jpg" for the show,
+// "<category>/<showName>/Season N.jpg" for a season poster, and
+// "<category>/<showName>/SxxEyy.jpg" for an episode still. GetVideos pairs
+// an image with a video only when their base names match exactly, so an
+// episode still is picked up only by an episode video named "SxxEyy", and
+// the show and season posters are not attached to the Show or its seasons.
+func (s *Service) FetchShowPoster(category, showName string, progressCb ProgressCallback) error {
+	sendProgress := func(step string, progress int) {
+		if progressCb != nil {
+			progressCb(step, progress)
+		}
+	}
+
+	sendProgress("Getting API key", 5)
+	apiKey := os.Getenv("TMDB_API_KEY")
+	if apiKey == "" {
+		return fmt.Errorf("TMDB_API_KEY environment variable not set")
+	}
+
+	sendProgress("Searching for show", 15)
+	cleanTitle, _ := parseMovieTitle(showName)
+	searchURL := fmt.Sprintf("%s?api_key=%s&query=%s", tmdbTVSearchURL, apiKey, url.QueryEscape(cleanTitle))
+	resp, err := http.Get(searchURL)
+	if err != nil {
+		return fmt.Errorf("failed to search show: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("TMDb API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result TMDbTVSearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode search result: %v", err)
+	}
+	if len(result.Results) == 0 {
+		return fmt.Errorf("no show found for title: %s", showName)
+	}
+	show := result.Results[0]
+
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create storage client: %v", err)
+	}
+	defer client.Close()
+	bucket := client.Bucket(s.config.BucketName)
+
+	if show.PosterPath != nil && *show.PosterPath != "" {
+		sendProgress("Uploading show poster", 30)
+		showPosterPath := strings.Join([]string{category, showName}, "/") + ".jpg"
+		if err := downloadAndUploadImage(ctx, bucket, tmdbImageBase+*show.PosterPath, showPosterPath); err != nil {
+			return fmt.Errorf("failed to upload show poster: %v", err)
+		}
+	}
+
+	// Walk seasons until TMDb returns a 404, uploading season posters and
+	// episode stills as we go.
+	for seasonNumber := 1; ; seasonNumber++ {
+		sendProgress(fmt.Sprintf("Fetching season %d", seasonNumber), 30+seasonNumber*5)
+
+		seasonURL := fmt.Sprintf("%s/%d/season/%d?api_key=%s", tmdbTVBaseURL, show.ID, seasonNumber, apiKey)
+		seasonResp, err := http.Get(seasonURL)
+		if err != nil {
+			return fmt.Errorf("failed to fetch season %d: %v", seasonNumber, err)
+		}
+		if seasonResp.StatusCode == http.StatusNotFound {
+			seasonResp.Body.Close()
+			break
+		}
+		if seasonResp.StatusCode != http.StatusOK {
+			seasonResp.Body.Close()
+			return fmt.Errorf("TMDb API error fetching season %d (status %d)", seasonNumber, seasonResp.StatusCode)
+		}
+
+		var season TMDbSeasonResult
+		err = json.NewDecoder(seasonResp.Body).Decode(&season)
+		seasonResp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode season %d: %v", seasonNumber, err)
+		}
+
+		if season.PosterPath != nil && *season.PosterPath != "" {
+			seasonPosterPath := fmt.Sprintf("%s/Season %d.jpg", strings.Join([]string{category, showName}, "/"), seasonNumber)
+			if err := downloadAndUploadImage(ctx, bucket, tmdbImageBase+*season.PosterPath, seasonPosterPath); err != nil {
+				return fmt.Errorf("failed to upload season %d poster: %v", seasonNumber, err)
+			}
+		}
+
+		for _, episode := range season.Episodes {
+			if episode.StillPath == nil || *episode.StillPath == "" {
+				continue
+			}
+			stillPath := fmt.Sprintf("%s/S%02dE%02d.jpg", strings.Join([]string{category, showName}, "/"), seasonNumber, episode.EpisodeNumber)
+			if err := downloadAndUploadImage(ctx, bucket, tmdbImageBase+*episode.StillPath, stillPath); err != nil {
+				return fmt.Errorf("failed to upload episode still S%02dE%02d: %v", seasonNumber, episode.EpisodeNumber, err)
+			}
+		}
+	}
+
+	sendProgress("Clearing cache", 95)
+	s.videoCache.Flush()
+
+	sendProgress("Complete", 100)
+	return nil
+}
+
+// downloadAndUploadImage downloads imageURL to a temp file and uploads it
+// to the bucket at dst, mirroring the save/upload steps FetchMoviePoster uses.
+func downloadAndUploadImage(ctx context.Context, bucket *storage.BucketHandle, imageURL, dst string) error {
+	resp, err := http.Get(imageURL)
+	if err != nil {
+		return fmt.Errorf("failed to download image: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download image (status %d)", resp.StatusCode)
+	}
+
+	outputDir := filepath.Join(os.TempDir(), "video-gallery-thumbnails")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	tmpPath := filepath.Join(outputDir, getSafeFilename(dst))
+	tmpFile, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpPath)
+
+	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to save image: %v", err)
+	}
+	tmpFile.Close()
+
+	bucket.Object(dst).Delete(ctx)
+	return uploadFile(ctx, bucket, tmpPath, dst)
+}
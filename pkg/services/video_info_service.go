@@ -0,0 +1,144 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"video-gallery/pkg/models"
+)
+
+// videoInfoMetadata* are the GCS custom metadata keys used to persist a
+// VideoInfo on a thumbnail object, mirroring the "blurhash" key already used
+// for LQIP placeholders.
+const (
+	videoInfoMetadataWidth      = "info-width"
+	videoInfoMetadataHeight     = "info-height"
+	videoInfoMetadataDuration   = "info-duration"
+	videoInfoMetadataFps        = "info-fps"
+	videoInfoMetadataBitrate    = "info-bitrate"
+	videoInfoMetadataVideoCodec = "info-video-codec"
+	videoInfoMetadataAudioCodec = "info-audio-codec"
+	videoInfoMetadataFormat     = "info-format"
+)
+
+// ffprobeOutput mirrors the subset of `ffprobe -show_streams -show_format`
+// output needed to populate a models.VideoInfo.
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType  string `json:"codec_type"`
+		CodecName  string `json:"codec_name"`
+		Width      int    `json:"width"`
+		Height     int    `json:"height"`
+		RFrameRate string `json:"r_frame_rate"`
+	} `json:"streams"`
+	Format struct {
+		Duration   string `json:"duration"`
+		BitRate    string `json:"bit_rate"`
+		FormatName string `json:"format_name"`
+	} `json:"format"`
+}
+
+// ProbeVideo runs ffprobe against videoPath and returns its technical
+// metadata (dimensions, duration, fps, bitrate, codecs, container format).
+func ProbeVideo(videoPath string) (*models.VideoInfo, error) {
+	cmd := exec.Command(
+		"ffprobe",
+		"-v", "quiet",
+		"-print_format", "json",
+		"-show_streams",
+		"-show_format",
+		videoPath,
+	)
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %v", err)
+	}
+
+	var probe ffprobeOutput
+	if err := json.Unmarshal(stdout.Bytes(), &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %v", err)
+	}
+
+	info := &models.VideoInfo{Format: probe.Format.FormatName}
+
+	if duration, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		info.Duration = duration
+	}
+	if bitrate, err := strconv.ParseInt(probe.Format.BitRate, 10, 64); err == nil {
+		info.Bitrate = bitrate
+	}
+
+	for _, stream := range probe.Streams {
+		switch stream.CodecType {
+		case "video":
+			info.Width = stream.Width
+			info.Height = stream.Height
+			info.VideoCodec = stream.CodecName
+			info.Fps = parseFrameRate(stream.RFrameRate)
+		case "audio":
+			info.AudioCodec = stream.CodecName
+		}
+	}
+
+	return info, nil
+}
+
+// parseFrameRate converts an ffprobe rational frame rate (e.g. "30000/1001")
+// into a float, returning 0 if it can't be parsed.
+func parseFrameRate(rate string) float64 {
+	parts := strings.SplitN(rate, "/", 2)
+	if len(parts) != 2 {
+		return 0
+	}
+	num, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0
+	}
+	den, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || den == 0 {
+		return 0
+	}
+	return num / den
+}
+
+// EncodeVideoInfoMetadata serializes a VideoInfo into the GCS custom
+// metadata keys used to persist it alongside a thumbnail object.
+func EncodeVideoInfoMetadata(info *models.VideoInfo) map[string]string {
+	return map[string]string{
+		videoInfoMetadataWidth:      strconv.Itoa(info.Width),
+		videoInfoMetadataHeight:     strconv.Itoa(info.Height),
+		videoInfoMetadataDuration:   strconv.FormatFloat(info.Duration, 'f', -1, 64),
+		videoInfoMetadataFps:        strconv.FormatFloat(info.Fps, 'f', -1, 64),
+		videoInfoMetadataBitrate:    strconv.FormatInt(info.Bitrate, 10),
+		videoInfoMetadataVideoCodec: info.VideoCodec,
+		videoInfoMetadataAudioCodec: info.AudioCodec,
+		videoInfoMetadataFormat:     info.Format,
+	}
+}
+
+// DecodeVideoInfoMetadata reconstructs a VideoInfo from GCS custom metadata,
+// returning nil if the object carries neither a width nor a duration key.
+func DecodeVideoInfoMetadata(metadata map[string]string) *models.VideoInfo {
+	if metadata[videoInfoMetadataWidth] == "" && metadata[videoInfoMetadataDuration] == "" {
+		return nil
+	}
+
+	info := &models.VideoInfo{
+		VideoCodec: metadata[videoInfoMetadataVideoCodec],
+		AudioCodec: metadata[videoInfoMetadataAudioCodec],
+		Format:     metadata[videoInfoMetadataFormat],
+	}
+	info.Width, _ = strconv.Atoi(metadata[videoInfoMetadataWidth])
+	info.Height, _ = strconv.Atoi(metadata[videoInfoMetadataHeight])
+	info.Duration, _ = strconv.ParseFloat(metadata[videoInfoMetadataDuration], 64)
+	info.Fps, _ = strconv.ParseFloat(metadata[videoInfoMetadataFps], 64)
+	info.Bitrate, _ = strconv.ParseInt(metadata[videoInfoMetadataBitrate], 10, 64)
+
+	return info
+}
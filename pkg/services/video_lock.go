@@ -0,0 +1,33 @@
+package services
+
+import "sync"
+
+// videoLockEntry lazily creates its mutex exactly once, via sync.Once, the
+// first time a given video path is locked.
+type videoLockEntry struct {
+	once sync.Once
+	mu   *sync.Mutex
+}
+
+var (
+	videoLocksMu sync.Mutex
+	videoLocks   = make(map[string]*videoLockEntry)
+)
+
+// lockVideo blocks until it holds the exclusive lock for videoPath and
+// returns a function that releases it. It guards ffmpeg invocations so two
+// concurrent thumbnail jobs (e.g. a bulk run and a single-file job) never
+// process the same source video at once.
+func lockVideo(videoPath string) func() {
+	videoLocksMu.Lock()
+	entry, ok := videoLocks[videoPath]
+	if !ok {
+		entry = &videoLockEntry{}
+		videoLocks[videoPath] = entry
+	}
+	videoLocksMu.Unlock()
+
+	entry.once.Do(func() { entry.mu = &sync.Mutex{} })
+	entry.mu.Lock()
+	return entry.mu.Unlock
+}
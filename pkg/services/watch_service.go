@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// pollInterval is how often the bucket listing is polled for changes when
+// no PUBSUB_TOPIC is configured. Because a rebuild only happens when the
+// listing actually differs from the previous snapshot, this interval also
+// acts as the debounce window for bursts of uploads.
+const pollInterval = 30 * time.Second
+
+// pubsubDebounce is how long WatchBucket waits after the last Pub/Sub
+// notification before rebuilding the index, so a burst of notifications
+// from a multi-file upload collapses into a single rebuild.
+const pubsubDebounce = 5 * time.Second
+
+var (
+	statusMu      sync.RWMutex
+	lastRefresh   time.Time
+	lastObjectSet map[string]int64
+	objectCount   int
+	nextScan      time.Time
+)
+
+// Status reports the current state of the background bucket watcher.
+type Status struct {
+	LastRefresh time.Time `json:"lastRefresh"`
+	ObjectCount int       `json:"objectCount"`
+	NextScan    time.Time `json:"nextScan,omitempty"`
+}
+
+// GetStatus returns the watcher's current status for the /status endpoint.
+func GetStatus() Status {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	return Status{
+		LastRefresh: lastRefresh,
+		ObjectCount: objectCount,
+		NextScan:    nextScan,
+	}
+}
+
+// WatchBucket runs for the lifetime of the process, keeping videoCache fresh
+// without relying on its TTL. It either polls the bucket listing on an
+// interval or, when PUBSUB_TOPIC is configured, subscribes to bucket
+// notifications and debounces bursts of events into a single rebuild.
+func WatchBucket(ctx context.Context) {
+	if appConfig.PubSubTopic != "" {
+		watchBucketPubSub(ctx)
+		return
+	}
+	watchBucketPolling(ctx)
+}
+
+func watchBucketPolling(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	// Seed the initial snapshot and index so GetVideos doesn't pay the
+	// full listing cost on the first request.
+	rebuildIndex(ctx)
+
+	for {
+		statusMu.Lock()
+		nextScan = time.Now().Add(pollInterval)
+		statusMu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			snapshot, err := listBucketSnapshot(ctx)
+			if err != nil {
+				log.Printf("WatchBucket: failed to list bucket: %v", err)
+				continue
+			}
+			if snapshotChanged(snapshot) {
+				log.Println("WatchBucket: detected bucket changes, rebuilding index")
+				applySnapshot(snapshot)
+			}
+		}
+	}
+}
+
+func watchBucketPubSub(ctx context.Context) {
+	client, err := pubsub.NewClient(ctx, appConfig.BucketName)
+	if err != nil {
+		log.Printf("WatchBucket: failed to create pubsub client, falling back to polling: %v", err)
+		watchBucketPolling(ctx)
+		return
+	}
+	defer client.Close()
+
+	sub := client.Subscription(appConfig.PubSubTopic)
+
+	var debounceMu sync.Mutex
+	var debounceTimer *time.Timer
+	scheduleRebuild := func() {
+		debounceMu.Lock()
+		defer debounceMu.Unlock()
+		if debounceTimer != nil {
+			debounceTimer.Stop()
+		}
+		debounceTimer = time.AfterFunc(pubsubDebounce, func() {
+			log.Println("WatchBucket: debounce elapsed, rebuilding index")
+			rebuildIndex(ctx)
+		})
+	}
+
+	err = sub.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
+		msg.Ack()
+		scheduleRebuild()
+	})
+	if err != nil {
+		log.Printf("WatchBucket: pubsub receive ended, falling back to polling: %v", err)
+		watchBucketPolling(ctx)
+	}
+}
+
+// rebuildIndex flushes videoCache and eagerly repopulates it, then updates
+// the snapshot used to detect future changes and the status shown at /status.
+func rebuildIndex(ctx context.Context) {
+	videoCache.Flush()
+	videos := GetVideos()
+
+	snapshot, err := listBucketSnapshot(ctx)
+	if err != nil {
+		log.Printf("WatchBucket: failed to snapshot bucket after rebuild: %v", err)
+		snapshot = make(map[string]int64)
+	}
+
+	statusMu.Lock()
+	lastObjectSet = snapshot
+	lastRefresh = time.Now()
+	objectCount = len(videos)
+	statusMu.Unlock()
+}
+
+func applySnapshot(snapshot map[string]int64) {
+	videoCache.Flush()
+	videos := GetVideos()
+
+	statusMu.Lock()
+	lastObjectSet = snapshot
+	lastRefresh = time.Now()
+	objectCount = len(videos)
+	statusMu.Unlock()
+}
+
+// snapshotChanged compares a freshly listed snapshot against the last known
+// one, returning true if any object was added, removed, or changed generation.
+func snapshotChanged(snapshot map[string]int64) bool {
+	statusMu.RLock()
+	previous := lastObjectSet
+	statusMu.RUnlock()
+
+	if len(previous) != len(snapshot) {
+		return true
+	}
+	for name, generation := range snapshot {
+		if prevGeneration, ok := previous[name]; !ok || prevGeneration != generation {
+			return true
+		}
+	}
+	return false
+}
+
+// listBucketSnapshot lists every object in the bucket, keyed by name with
+// its generation number, used to cheaply diff successive polls.
+func listBucketSnapshot(ctx context.Context) (map[string]int64, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage client: %v", err)
+	}
+	defer client.Close()
+
+	bucket := client.Bucket(appConfig.BucketName)
+	it := bucket.Objects(ctx, nil)
+
+	snapshot := make(map[string]int64)
+	for {
+		obj, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error iterating objects: %v", err)
+		}
+		snapshot[obj.Name] = obj.Generation
+	}
+	return snapshot, nil
+}